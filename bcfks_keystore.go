@@ -0,0 +1,383 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jvmvendors
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf16"
+)
+
+// The BCFKS format is the Bouncy Castle FIPS keystore used by FIPS-enabled JVM distributions. Only reading the subset
+// needed for truststores is supported: certificate entries, PBKDF2 with HMAC-SHA512 key derivation, an HMAC-SHA512
+// integrity check and AES-256-CCM store encryption.
+
+var (
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES256CCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 47}
+)
+
+const (
+	bcfksCertificate       = 0
+	bcfksEncryptionKeySize = 32
+)
+
+type bcfksObjectStore struct {
+	StoreData      asn1.RawValue
+	IntegrityCheck asn1.RawValue
+}
+
+type bcfksEncryptedObjectStoreData struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent    []byte
+}
+
+type bcfksObjectStoreData struct {
+	Version            int
+	IntegrityAlgorithm pkix.AlgorithmIdentifier
+	CreationDate       time.Time `asn1:"generalized"`
+	LastModifiedDate   time.Time `asn1:"generalized"`
+	ObjectDataSequence []bcfksObjectData
+	Comment            string `asn1:"optional,utf8"`
+}
+
+type bcfksObjectData struct {
+	Type             int
+	Identifier       string    `asn1:"utf8"`
+	CreationDate     time.Time `asn1:"generalized"`
+	LastModifiedDate time.Time `asn1:"generalized"`
+	Data             []byte
+	Comment          string `asn1:"optional,utf8"`
+}
+
+type bcfksPbkdMacIntegrityCheck struct {
+	MacAlgorithm  pkix.AlgorithmIdentifier
+	PbkdAlgorithm pkix.AlgorithmIdentifier
+	Mac           []byte
+}
+
+type bcfksPBES2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type bcfksPBKDF2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type bcfksCCMParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"optional,default:12"`
+}
+
+var _ Keystore = &BCFKSKeystore{}
+
+type BCFKSKeystore struct {
+	store bcfksObjectStoreData
+}
+
+func NewBCFKSKeystore(location, password string) (*BCFKSKeystore, error) {
+	in, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", location, err)
+	}
+
+	var objectStore bcfksObjectStore
+	if err := unmarshalDER(in, &objectStore); err != nil {
+		return nil, fmt.Errorf("unable to decode BCFKS keystore\n%w", err)
+	}
+
+	var integrityCheck bcfksPbkdMacIntegrityCheck
+	if err := unmarshalDER(objectStore.IntegrityCheck.FullBytes, &integrityCheck); err != nil {
+		return nil, fmt.Errorf("unable to decode BCFKS integrity check, only password based MACs are supported\n%w", err)
+	}
+
+	if !integrityCheck.MacAlgorithm.Algorithm.Equal(oidHMACWithSHA512) {
+		return nil, fmt.Errorf("unsupported BCFKS MAC algorithm %s", integrityCheck.MacAlgorithm.Algorithm)
+	}
+
+	macKDF, err := parseBCFKSPBKDF2(integrityCheck.PbkdAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode BCFKS integrity check key derivation\n%w", err)
+	}
+
+	mac, err := bcfksMAC(objectStore.StoreData.FullBytes, password, macKDF)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, integrityCheck.Mac) {
+		return nil, errors.New("BCFKS keystore integrity check failed, the keystore password is incorrect")
+	}
+
+	k := &BCFKSKeystore{}
+
+	storeData := objectStore.StoreData.FullBytes
+	if isBCFKSEncrypted(objectStore.StoreData) {
+		var encrypted bcfksEncryptedObjectStoreData
+		if err := unmarshalDER(storeData, &encrypted); err != nil {
+			return nil, fmt.Errorf("unable to decode BCFKS encrypted store data\n%w", err)
+		}
+
+		if storeData, err = bcfksDecrypt(encrypted, password); err != nil {
+			return nil, fmt.Errorf("unable to decrypt BCFKS store data\n%w", err)
+		}
+	}
+
+	if err := unmarshalDER(storeData, &k.store); err != nil {
+		return nil, fmt.Errorf("unable to decode BCFKS store data\n%w", err)
+	}
+
+	return k, nil
+}
+
+// Add returns ErrReadOnlyKeystore, as there is no independent check that Bouncy Castle reads a BCFKS keystore written
+// by this package.
+func (k *BCFKSKeystore) Add(string, *pem.Block) error {
+	return ErrReadOnlyKeystore
+}
+
+func (k *BCFKSKeystore) Entries() ([]KeystoreEntry, error) {
+	var entries []KeystoreEntry
+	for _, o := range k.store.ObjectDataSequence {
+		if o.Type != bcfksCertificate {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(o.Data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate %s\n%w", o.Identifier, err)
+		}
+
+		entries = append(entries, KeystoreEntry{Alias: o.Identifier, Certificate: cert})
+	}
+	return entries, nil
+}
+
+// Write does nothing, as adding certificates to BCFKS keystores is not supported.
+func (k *BCFKSKeystore) Write() error {
+	return nil
+}
+
+func (k *BCFKSKeystore) Len() int {
+	return len(k.store.ObjectDataSequence)
+}
+
+// isBCFKSEncrypted distinguishes EncryptedObjectStoreData, which starts with an AlgorithmIdentifier, from plain
+// ObjectStoreData, which starts with a version number.
+func isBCFKSEncrypted(storeData asn1.RawValue) bool {
+	var first asn1.RawValue
+	if _, err := asn1.Unmarshal(storeData.Bytes, &first); err != nil {
+		return false
+	}
+	return first.Tag == asn1.TagSequence
+}
+
+func bcfksDecrypt(encrypted bcfksEncryptedObjectStoreData, password string) ([]byte, error) {
+	if !encrypted.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", encrypted.EncryptionAlgorithm.Algorithm)
+	}
+
+	var params bcfksPBES2Params
+	if err := unmarshalDER(encrypted.EncryptionAlgorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("unable to decode PBES2 parameters\n%w", err)
+	}
+
+	if !params.EncryptionScheme.Algorithm.Equal(oidAES256CCM) {
+		return nil, fmt.Errorf("unsupported encryption scheme %s", params.EncryptionScheme.Algorithm)
+	}
+
+	var ccm bcfksCCMParams
+	if err := unmarshalDER(params.EncryptionScheme.Parameters.FullBytes, &ccm); err != nil {
+		return nil, fmt.Errorf("unable to decode CCM parameters\n%w", err)
+	}
+
+	kdf, err := parseBCFKSPBKDF2(params.KeyDerivationFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := kdf.deriveKey(password, "STORE_ENCRYPTION", bcfksEncryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := ccmOpen(block, ccm.Nonce, encrypted.EncryptedContent, ccm.ICVLen)
+	if err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+func bcfksMAC(data []byte, password string, kdf bcfksPBKDF2Params) ([]byte, error) {
+	key, err := kdf.deriveKey(password, "INTEGRITY_CHECK", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func parseBCFKSPBKDF2(algorithm pkix.AlgorithmIdentifier) (bcfksPBKDF2Params, error) {
+	if !algorithm.Algorithm.Equal(oidPBKDF2) {
+		return bcfksPBKDF2Params{}, fmt.Errorf("unsupported key derivation function %s", algorithm.Algorithm)
+	}
+
+	var params bcfksPBKDF2Params
+	if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+		return bcfksPBKDF2Params{}, fmt.Errorf("unable to decode PBKDF2 parameters\n%w", err)
+	}
+
+	if !params.PRF.Algorithm.Equal(oidHMACWithSHA512) {
+		return bcfksPBKDF2Params{}, fmt.Errorf("unsupported PBKDF2 pseudo-random function %s", params.PRF.Algorithm)
+	}
+
+	return params, nil
+}
+
+// deriveKey mirrors the Bouncy Castle key derivation, which mixes a purpose string into the password so that the
+// integrity and encryption keys differ even when they share a salt.
+func (p bcfksPBKDF2Params) deriveKey(password string, purpose string, defaultKeyLength int) ([]byte, error) {
+	keyLength := p.KeyLength
+	if keyLength == 0 {
+		keyLength = defaultKeyLength
+	}
+	if keyLength == 0 {
+		return nil, errors.New("no key length found in PBKDF2 parameters")
+	}
+
+	secret := append(pkcs12PasswordBytes(password), pkcs12PasswordBytes(purpose)...)
+	return pbkdf2.Key(sha512.New, string(secret), p.Salt, p.IterationCount, keyLength)
+}
+
+// pkcs12PasswordBytes encodes a password as a null-terminated big-endian UTF-16 string.
+func pkcs12PasswordBytes(password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+
+	var b []byte
+	for _, c := range utf16.Encode([]rune(password)) {
+		b = binary.BigEndian.AppendUint16(b, c)
+	}
+	return append(b, 0, 0)
+}
+
+func unmarshalDER(in []byte, out any) error {
+	rest, err := asn1.Unmarshal(in, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data after ASN.1 structure")
+	}
+	return nil
+}
+
+// ccmOpen implements AES-CCM (NIST SP 800-38C) decryption without associated data, which the standard library does
+// not provide.
+func ccmOpen(block cipher.Block, nonce []byte, ciphertext []byte, tagSize int) ([]byte, error) {
+	if len(nonce) < 7 || len(nonce) > 13 {
+		return nil, fmt.Errorf("invalid CCM nonce length %d", len(nonce))
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 || len(ciphertext) < tagSize {
+		return nil, fmt.Errorf("invalid CCM tag length %d", tagSize)
+	}
+
+	n := len(ciphertext) - tagSize
+	plaintext := make([]byte, n)
+	ccmCTR(block, nonce, plaintext, ciphertext[:n])
+
+	tag := make([]byte, tagSize)
+	ccmCTR0(block, nonce, tag, ciphertext[n:])
+
+	if subtle.ConstantTimeCompare(tag, ccmMAC(block, nonce, plaintext, tagSize)) != 1 {
+		return nil, errors.New("CCM authentication failed")
+	}
+
+	return plaintext, nil
+}
+
+func ccmMAC(block cipher.Block, nonce []byte, plaintext []byte, tagSize int) []byte {
+	l := 15 - len(nonce)
+
+	b := make([]byte, aes.BlockSize)
+	b[0] = byte(((tagSize-2)/2)<<3 | (l - 1))
+	copy(b[1:], nonce)
+	for i, n := aes.BlockSize-1, len(plaintext); i > len(nonce); i, n = i-1, n>>8 {
+		b[i] = byte(n)
+	}
+
+	mac := make([]byte, aes.BlockSize)
+	block.Encrypt(mac, b)
+
+	for i := 0; i < len(plaintext); i += aes.BlockSize {
+		end := min(i+aes.BlockSize, len(plaintext))
+		subtle.XORBytes(mac, mac, plaintext[i:end])
+		block.Encrypt(mac, mac)
+	}
+
+	return mac[:tagSize]
+}
+
+func ccmCounter(nonce []byte, i uint64) []byte {
+	l := 15 - len(nonce)
+
+	a := make([]byte, aes.BlockSize)
+	a[0] = byte(l - 1)
+	copy(a[1:], nonce)
+	for j := aes.BlockSize - 1; j > len(nonce); j, i = j-1, i>>8 {
+		a[j] = byte(i)
+	}
+	return a
+}
+
+func ccmCTR(block cipher.Block, nonce []byte, dst []byte, src []byte) {
+	stream := cipher.NewCTR(block, ccmCounter(nonce, 1))
+	stream.XORKeyStream(dst, src)
+}
+
+func ccmCTR0(block cipher.Block, nonce []byte, dst []byte, src []byte) {
+	s0 := make([]byte, aes.BlockSize)
+	block.Encrypt(s0, ccmCounter(nonce, 0))
+	subtle.XORBytes(dst, src, s0)
+}
@@ -113,6 +113,11 @@ func (b Build) Build(context libcnb.BuildContext, result *libcnb.BuildResult) ([
 	}
 	cr.LogConfiguration(b.Logger)
 
+	if password, ok := cr.Resolve("BP_JVM_CACERTS_PASSWORD"); ok {
+		b.CertLoader.Password = password
+	}
+	b.CertLoader.IncludeAllFiles = cr.ResolveBool("BP_JVM_CACERTS_INCLUDE_ALL_FILES")
+
 	dr, err := libpak.NewDependencyResolver(bpm, context.StackID) //nolint:staticcheck
 	if err != nil {
 		return []libpak.Contributable{}, fmt.Errorf("unable to create dependency resolver\n%w", err)
@@ -299,22 +304,21 @@ func (b *Build) contributeNIK(jdkDep libpak.BuildModuleDependency, nativeDep lib
 }
 
 func (b *Build) contributeHelpers(context libcnb.BuildContext, depJRE libpak.BuildModuleDependency) error {
-	helpers := []string{"java-opts", "jvm-heap", "link-local-dns", "memory-calculator",
-		"security-providers-configurer", "jmx", "jfr", "openssl-certificate-loader"}
+	helpers := []string{"java-agents", "java-opts", "jvm-gc", "jvm-heap", "link-local-dns", "memory-calculator",
+		"security-providers-configurer", "jmx", "jfr", "openssl-certificate-loader", "tls-client-keystore",
+		"active-processor-count"}
 
 	if IsBeforeJava9(depJRE.Version) {
 		helpers = append(helpers, "security-providers-classpath-8")
 		helpers = append(helpers, "debug-8")
+		helpers = append(helpers, "gc-log-8")
 	} else {
 		helpers = append(helpers, "security-providers-classpath-9")
 		helpers = append(helpers, "debug-9")
+		helpers = append(helpers, "gc-log-9")
 		helpers = append(helpers, "nmt")
 	}
 
-	if IsBeforeJava17(depJRE.Version) {
-		helpers = append(helpers, "active-processor-count")
-	}
-
 	found := false
 	for _, custom := range b.CustomHelpers {
 		if found {
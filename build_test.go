@@ -233,7 +233,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(contributors[1].Name()).To(Equal("helper"))
 
 		Expect(contributors[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{
+			"java-agents",
 			"java-opts",
+			"jvm-gc",
 			"jvm-heap",
 			"link-local-dns",
 			"memory-calculator",
@@ -241,9 +243,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			"jmx",
 			"jfr",
 			"openssl-certificate-loader",
+			"tls-client-keystore",
+			"active-processor-count",
 			"security-providers-classpath-8",
 			"debug-8",
-			"active-processor-count",
+			"gc-log-8",
 		}))
 	})
 
@@ -266,7 +270,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(contributors[1].Name()).To(Equal("helper"))
 
 		Expect(contributors[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{
+			"java-agents",
 			"java-opts",
+			"jvm-gc",
 			"jvm-heap",
 			"link-local-dns",
 			"memory-calculator",
@@ -274,44 +280,16 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			"jmx",
 			"jfr",
 			"openssl-certificate-loader",
-			"security-providers-classpath-9",
-			"debug-9",
-			"nmt",
+			"tls-client-keystore",
 			"active-processor-count",
-		}))
-	})
-
-	it("contributes active-processor-count before Java 17", func() {
-		ctx.Plan.Entries = append(ctx.Plan.Entries, libcnb.BuildpackPlanEntry{Name: "jre", Metadata: LaunchContribution})
-		ctx.Buildpack.Metadata["dependencies"] = []map[string]any{
-			{
-				"id":      "jre-corretto",
-				"version": "11.0.0",
-				"stacks":  []interface{}{"test-stack-id"},
-			},
-		}
-		ctx.StackID = "test-stack-id" //nolint:staticcheck
-
-		contributors, err := jvmvendors.NewBuild(log.NewPaketoLogger(io.Discard)).Build(ctx, &result)
-		Expect(err).NotTo(HaveOccurred())
-
-		Expect(contributors[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{
-			"java-opts",
-			"jvm-heap",
-			"link-local-dns",
-			"memory-calculator",
-			"security-providers-configurer",
-			"jmx",
-			"jfr",
-			"openssl-certificate-loader",
 			"security-providers-classpath-9",
 			"debug-9",
+			"gc-log-9",
 			"nmt",
-			"active-processor-count",
 		}))
 	})
 
-	it("does not contribute active-processor-count with Java 17 and later", func() {
+	it("contributes active-processor-count with Java 17 and later", func() {
 		ctx.Plan.Entries = append(ctx.Plan.Entries, libcnb.BuildpackPlanEntry{Name: "jre", Metadata: LaunchContribution})
 		ctx.Buildpack.Metadata["dependencies"] = []map[string]any{
 			{
@@ -326,7 +304,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(contributors[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{
+			"java-agents",
 			"java-opts",
+			"jvm-gc",
 			"jvm-heap",
 			"link-local-dns",
 			"memory-calculator",
@@ -334,8 +314,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			"jmx",
 			"jfr",
 			"openssl-certificate-loader",
+			"tls-client-keystore",
+			"active-processor-count",
 			"security-providers-classpath-9",
 			"debug-9",
+			"gc-log-9",
 			"nmt",
 		}))
 	})
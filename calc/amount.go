@@ -0,0 +1,181 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	AmountFunctionRE   = regexp.MustCompile(`^(max|min)\((.*)\)$`)
+	AmountPercentageRE = regexp.MustCompile(`^([\d]+(?:\.[\d]+)?)%$`)
+)
+
+// Amount is an amount of memory relative to the total memory: an absolute size such as 256M, a percentage such as 5%,
+// or the largest or smallest of other amounts such as max(5%, 128M). The zero value is an absolute size of zero.
+type Amount struct {
+	Function   string
+	Operands   []Amount
+	Percentage *float64
+	Size       *Size
+}
+
+// AbsoluteAmount returns an amount that is always the given number of bytes.
+func AbsoluteAmount(bytes int64) Amount {
+	return Amount{Size: &Size{Value: bytes}}
+}
+
+// PercentageAmount returns an amount that is the given percentage of total memory.
+func PercentageAmount(percentage float64) Amount {
+	return Amount{Percentage: &percentage}
+}
+
+// ParseAmount parses an amount from the given string. Percentages must be between 0 and 100, and sizes may have any
+// suffix ParseSize accepts. A bare number is a size in bytes.
+func ParseAmount(s string) (Amount, error) {
+	t := strings.TrimSpace(s)
+
+	if g := AmountFunctionRE.FindStringSubmatch(t); g != nil {
+		a := Amount{Function: g[1]}
+
+		for _, o := range splitOperands(g[2]) {
+			p, err := ParseAmount(o)
+			if err != nil {
+				return Amount{}, fmt.Errorf("unable to parse operand of %s\n%w", t, err)
+			}
+			a.Operands = append(a.Operands, p)
+		}
+
+		if len(a.Operands) == 0 {
+			return Amount{}, fmt.Errorf("amount %q has no operands", t)
+		}
+		return a, nil
+	}
+
+	if g := AmountPercentageRE.FindStringSubmatch(t); g != nil {
+		p, err := strconv.ParseFloat(g[1], 64)
+		if err != nil {
+			return Amount{}, fmt.Errorf("percentage %q is not a number", g[1])
+		}
+		if p > 100 {
+			return Amount{}, fmt.Errorf("percentage %q must be between 0 and 100", t)
+		}
+		return PercentageAmount(p), nil
+	}
+
+	z, err := ParseSize(t)
+	if err != nil {
+		return Amount{}, fmt.Errorf("amount %q is not a size, a percentage or max() or min() of amounts\n%w", t, err)
+	}
+	return AbsoluteAmount(z.Value), nil
+}
+
+// splitOperands splits s on the commas that are not nested in parentheses.
+func splitOperands(s string) []string {
+	var (
+		o     []string
+		depth int
+		start int
+	)
+
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				o = append(o, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if strings.TrimSpace(s[start:]) != "" || len(o) > 0 {
+		o = append(o, s[start:])
+	}
+
+	return o
+}
+
+// Of returns the number of bytes the amount is of total memory.
+func (a Amount) Of(totalMemory int64) int64 {
+	switch {
+	case a.Function != "":
+		v := a.Operands[0].Of(totalMemory)
+		for _, o := range a.Operands[1:] {
+			if a.Function == "max" {
+				v = max(v, o.Of(totalMemory))
+			} else {
+				v = min(v, o.Of(totalMemory))
+			}
+		}
+		return v
+	case a.Percentage != nil:
+		return int64(*a.Percentage / 100 * float64(totalMemory))
+	case a.Size != nil:
+		return a.Size.Value
+	default:
+		return 0
+	}
+}
+
+// IsZero returns whether the amount is the zero value.
+func (a Amount) IsZero() bool {
+	return a.Function == "" && a.Percentage == nil && a.Size == nil
+}
+
+// IsAbsolute returns whether the amount is the same for any total memory.
+func (a Amount) IsAbsolute() bool {
+	return a.Function == "" && a.Percentage == nil
+}
+
+func (a Amount) String() string {
+	switch {
+	case a.Function != "":
+		s := make([]string, len(a.Operands))
+		for i, o := range a.Operands {
+			s[i] = o.String()
+		}
+		return fmt.Sprintf("%s(%s)", a.Function, strings.Join(s, ", "))
+	case a.Percentage != nil:
+		return strconv.FormatFloat(*a.Percentage, 'f', -1, 64) + "%"
+	case a.Size != nil:
+		return a.Size.String()
+	default:
+		return "0"
+	}
+}
+
+// MarshalText marshals the amount as its string form.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses the amount from its string form.
+func (a *Amount) UnmarshalText(text []byte) error {
+	p, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = p
+	return nil
+}
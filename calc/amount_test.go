@@ -0,0 +1,122 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+func testAmount(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("parse", func() {
+		it("parses sizes", func() {
+			Expect(calc.ParseAmount("256M")).To(Equal(calc.AbsoluteAmount(256 * calc.Mebi)))
+		})
+
+		it("parses bare numbers as bytes", func() {
+			Expect(calc.ParseAmount("1024")).To(Equal(calc.AbsoluteAmount(calc.Kibi)))
+		})
+
+		it("parses percentages", func() {
+			Expect(calc.ParseAmount("2.5%")).To(Equal(calc.PercentageAmount(2.5)))
+		})
+
+		it("parses max and min", func() {
+			Expect(calc.ParseAmount("max(5%, min(128M, 10%))")).To(Equal(calc.Amount{
+				Function: "max",
+				Operands: []calc.Amount{
+					calc.PercentageAmount(5),
+					{Function: "min", Operands: []calc.Amount{calc.AbsoluteAmount(128 * calc.Mebi), calc.PercentageAmount(10)}},
+				},
+			}))
+		})
+
+		it("returns error for percentages above 100", func() {
+			_, err := calc.ParseAmount("101%")
+			Expect(err).To(MatchError(ContainSubstring("must be between 0 and 100")))
+		})
+
+		it("returns error for functions without operands", func() {
+			_, err := calc.ParseAmount("max()")
+			Expect(err).To(MatchError(ContainSubstring("has no operands")))
+		})
+
+		it("returns error for invalid operands", func() {
+			_, err := calc.ParseAmount("max(5%, )")
+			Expect(err).To(HaveOccurred())
+		})
+
+		it("returns error for unknown amounts", func() {
+			_, err := calc.ParseAmount("avg(5%, 128M)")
+			Expect(err).To(MatchError(ContainSubstring("is not a size, a percentage or max() or min() of amounts")))
+		})
+	})
+
+	context("of", func() {
+		it("returns sizes regardless of total memory", func() {
+			Expect(calc.AbsoluteAmount(256 * calc.Mebi).Of(32 * calc.Gibi)).To(Equal(256 * calc.Mebi))
+		})
+
+		it("returns percentages of total memory", func() {
+			Expect(calc.PercentageAmount(5).Of(calc.Gibi)).To(Equal(int64(53687091)))
+		})
+
+		it("returns the largest or smallest operand", func() {
+			a, err := calc.ParseAmount("max(5%, 128M)")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Of(512 * calc.Mebi)).To(Equal(128 * calc.Mebi))
+			Expect(a.Of(32 * calc.Gibi)).To(Equal(int64(1717986918)))
+
+			a, err = calc.ParseAmount("min(10%, 1G)")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Of(512 * calc.Mebi)).To(Equal(int64(53687091)))
+			Expect(a.Of(32 * calc.Gibi)).To(Equal(calc.Gibi))
+		})
+
+		it("returns zero for the zero value", func() {
+			Expect(calc.Amount{}.Of(calc.Gibi)).To(BeZero())
+		})
+	})
+
+	it("formats", func() {
+		a, err := calc.ParseAmount("max( 5% ,128m )")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.String()).To(Equal("max(5%, 128M)"))
+	})
+
+	it("round trips through JSON", func() {
+		a, err := calc.ParseAmount("min(12.5%, 1G)")
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := json.Marshal(a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`"min(12.5%, 1G)"`))
+
+		var r calc.Amount
+		Expect(json.Unmarshal(b, &r)).To(Succeed())
+		Expect(r).To(Equal(a))
+	})
+}
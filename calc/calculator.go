@@ -18,6 +18,10 @@ package calc
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
 )
 
 const (
@@ -26,16 +30,25 @@ const (
 )
 
 type Output struct {
-	Memory      MemoryRegions
-	ThreadCount ThreadCount
+	ClassMetadata   ClassMetadata
+	LowProfileFlags []string
+	Memory          MemoryRegions
+	ThreadCount     ThreadCount
 }
 
 type Calculator struct {
-	HeadRoom         int
-	LoadedClassCount int
-	LowProfile       bool
-	ThreadCount      ThreadCount
-	TotalMemory      Size
+	ClassSize            int64
+	DirectMemory         *Amount
+	GC                   string
+	HeadRoom             Amount
+	JavaVersion          int
+	LoadedClassCount     int
+	LowProfile           bool
+	MallocArenas         int
+	NativeMemoryTracking string
+	ReservedCodeCache    *Amount
+	ThreadCount          ThreadCount
+	TotalMemory          Size
 }
 
 func (c *Calculator) applyLowProfileScaling(m *MemoryRegions, threadCount *ThreadCount) {
@@ -50,7 +63,7 @@ func (c *Calculator) applyLowProfileScaling(m *MemoryRegions, threadCount *Threa
 		m.Stack = Stack{Value: scaled, Provenance: Calculated}
 	}
 
-	if m.ReservedCodeCache.Provenance != UserConfigured {
+	if m.ReservedCodeCache.Provenance != UserConfigured && c.ReservedCodeCache == nil {
 		scaled := int64(float64(DefaultReservedCodeCache.Value) * scalingFactor)
 		if scaled < MinCodeCacheSize {
 			scaled = MinCodeCacheSize
@@ -58,7 +71,8 @@ func (c *Calculator) applyLowProfileScaling(m *MemoryRegions, threadCount *Threa
 		m.ReservedCodeCache = ReservedCodeCache{Value: scaled, Provenance: Calculated}
 	}
 
-	if threadCount.Provenance != UserConfigured {
+	// thread counts configured by the user or inferred from the application are what the application needs
+	if threadCount.Provenance == Default {
 		scaled := int(float64(DefaultThreadCount) * scalingFactor)
 		if scaled < MinThreadCount {
 			scaled = MinThreadCount
@@ -68,25 +82,63 @@ func (c *Calculator) applyLowProfileScaling(m *MemoryRegions, threadCount *Threa
 }
 
 func (c *Calculator) Calculate(flags string) (Output, error) {
-	m, err := NewMemoryRegionsFromFlags(flags)
+	m, err := NewMemoryRegionsFromFlags(flags, c.TotalMemory)
 	if err != nil {
 		return Output{}, fmt.Errorf("unable to create memory regions from flags\n%w", err)
 	}
 
+	// sizes configured in flags take precedence over the calculator's amounts
+	if c.DirectMemory != nil && m.DirectMemory.Provenance != UserConfigured {
+		m.DirectMemory = DirectMemory{Value: c.DirectMemory.Of(c.TotalMemory.Value), Provenance: Calculated}
+	}
+	if c.ReservedCodeCache != nil && m.ReservedCodeCache.Provenance != UserConfigured {
+		m.ReservedCodeCache = ReservedCodeCache{
+			Value:      max(c.ReservedCodeCache.Of(c.TotalMemory.Value), MinCodeCacheSize),
+			Provenance: Calculated,
+		}
+	}
+
 	// work with a local copy, so c.ThreadCount is never mutated
 	threadCount := c.ThreadCount
 
+	var lowProfileFlags []string
 	if c.LowProfile {
 		c.applyLowProfileScaling(&m, &threadCount)
+
+		if lowProfileFlags, err = c.lowProfileFlags(flags); err != nil {
+			return Output{}, fmt.Errorf("unable to determine low profile flags\n%w", err)
+		}
+	}
+
+	all := strings.Join(append([]string{flags}, lowProfileFlags...), " ")
+
+	cm, err := c.classMetadata(all)
+	if err != nil {
+		return Output{}, fmt.Errorf("unable to determine class metadata\n%w", err)
 	}
 
 	if m.Metaspace == nil {
+		// the JVM's own metadata shrinks along with the fewer threads and compilers of the low profile
+		if c.LowProfile {
+			cm.ClassOverhead = max(int64(float64(cm.ClassOverhead)*m.ScalingFactor), MinClassOverhead)
+		}
+
+		// compact object headers leave an alignment gap after each class in the compressed class space
+		classSize := cm.ClassSize
+		if compactObjectHeaders(all) {
+			classSize += CompactHeadersClassSize
+		}
+
 		m.Metaspace = &Metaspace{
-			Value:      ClassOverhead + (ClassSize * int64(c.LoadedClassCount)),
+			Value:      cm.ClassOverhead + (classSize * int64(max(c.LoadedClassCount-cm.SharedClassCount, 0))),
 			Provenance: Calculated,
 		}
 	}
 
+	if m.NativeOverhead, err = c.nativeOverhead(all, m); err != nil {
+		return Output{}, fmt.Errorf("unable to calculate native overhead\n%w", err)
+	}
+
 	f, err := m.FixedRegionsSize(threadCount.Value)
 	if err != nil {
 		return Output{}, fmt.Errorf("unable to calculate fixed regions size\n%w", err)
@@ -99,10 +151,7 @@ func (c *Calculator) Calculate(flags string) (Output, error) {
 		)
 	}
 
-	m.HeadRoom = &HeadRoom{
-		Value:      int64((float64(c.HeadRoom) / 100) * float64(c.TotalMemory.Value)),
-		Provenance: Calculated,
-	}
+	m.HeadRoom = &HeadRoom{Value: c.HeadRoom.Of(c.TotalMemory.Value), Provenance: c.headRoomProvenance()}
 
 	n, err := m.NonHeapRegionsSize(threadCount.Value)
 	if err != nil {
@@ -134,6 +183,10 @@ func (c *Calculator) Calculate(flags string) (Output, error) {
 			a, c.TotalMemory, m.AllRegionsString(threadCount.Value))
 	}
 
+	if m.InitialHeap != nil && m.InitialHeap.Value > m.Heap.Value {
+		return Output{}, fmt.Errorf("initial heap size %s is greater than the maximum heap size %s", m.InitialHeap, m.Heap)
+	}
+
 	if m.Heap.Value < MinHeapSize {
 		return Output{}, fmt.Errorf(
 			"calculated heap size (%d) is less than the JVM minimum of %s. To resolve this, reduce one or more of: thread stack size (-Xss), currently: %s. thread count ($BPL_JVM_THREAD_COUNT), currently: %d. code cache size (-XX:ReservedCodeCacheSize), currently: %s",
@@ -141,5 +194,137 @@ func (c *Calculator) Calculate(flags string) (Output, error) {
 		)
 	}
 
-	return Output{Memory: m, ThreadCount: threadCount}, nil
+	return Output{ClassMetadata: cm, LowProfileFlags: lowProfileFlags, Memory: m, ThreadCount: threadCount}, nil
+}
+
+// MinimumTotalMemory returns the smallest total memory, to the mebibyte and no larger than limit, for which the
+// calculation succeeds. As at launch, the low profile is only applied to total memory below LowProfileThreshold.
+func (c *Calculator) MinimumTotalMemory(flags string, limit Size) (Size, error) {
+	calculate := func(mebibytes int64) error {
+		d := *c
+		d.TotalMemory = Size{Value: mebibytes * Mebi}
+		d.LowProfile = c.LowProfile && d.TotalMemory.Value < LowProfileThreshold
+		_, err := d.Calculate(flags)
+		return err
+	}
+
+	hi := limit.Value / Mebi
+	if err := calculate(hi); err != nil {
+		return Size{}, fmt.Errorf("unable to calculate memory configuration for %s\n%w", Size{Value: hi * Mebi}, err)
+	}
+
+	// the regions grow with total memory, so there is a single point at which the calculation starts succeeding
+	lo := int64(0)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if calculate(mid) == nil {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return Size{Value: hi * Mebi, Provenance: Calculated}, nil
+}
+
+// headRoomProvenance returns Default if no headroom has been configured, UserConfigured if it is an absolute size and
+// Calculated if it depends on the total memory.
+func (c *Calculator) headRoomProvenance() Provenance {
+	switch {
+	case c.HeadRoom.IsZero():
+		return Default
+	case c.HeadRoom.IsAbsolute():
+		return UserConfigured
+	default:
+		return Calculated
+	}
+}
+
+// classMetadata returns the class metadata sizes of the Java version, with the class size overridden if the calculator
+// has one. Classes in the default CDS archive are shared unless CDS has been disabled in flags.
+func (c *Calculator) classMetadata(flags string) (ClassMetadata, error) {
+	p, err := shellwords.Parse(flags)
+	if err != nil {
+		return ClassMetadata{}, fmt.Errorf("unable to parse flags\n%w", err)
+	}
+
+	m := ClassMetadataFor(c.JavaVersion)
+	if c.ClassSize > 0 {
+		m.ClassSize = c.ClassSize
+	}
+
+	if c.JavaVersion >= 12 && !slices.Contains(p, "-Xshare:off") {
+		m.SharedClassCount = DefaultCDSArchiveClassCount
+	}
+
+	return m, nil
+}
+
+// nativeOverhead estimates the native overhead, preferring the GC and Native Memory Tracking level configured in flags
+// over those of the calculator. Without either, the GC is the one the JVM would select.
+func (c *Calculator) nativeOverhead(flags string, m MemoryRegions) (NativeOverhead, error) {
+	p, err := shellwords.Parse(flags)
+	if err != nil {
+		return NativeOverhead{}, fmt.Errorf("unable to parse flags\n%w", err)
+	}
+
+	gc, nmt, zGenerational := c.GC, c.NativeMemoryTracking, c.JavaVersion >= 23
+	for _, f := range p {
+		switch f {
+		case "-XX:+UseEpsilonGC":
+			gc = GCEpsilon
+		case "-XX:+UseG1GC":
+			gc = GCG1
+		case "-XX:+UseParallelGC":
+			gc = GCParallel
+		case "-XX:+UseSerialGC":
+			gc = GCSerial
+		case "-XX:+UseShenandoahGC":
+			gc = GCShenandoah
+		case "-XX:+UseZGC":
+			gc = GCZ
+		case "-XX:+ZGenerational":
+			zGenerational = true
+		case "-XX:-ZGenerational":
+			zGenerational = false
+		default:
+			if s, ok := strings.CutPrefix(f, "-XX:NativeMemoryTracking="); ok {
+				nmt = s
+			}
+		}
+	}
+
+	if gc == "" {
+		gc = GCG1
+		if c.TotalMemory.Value < ServerClassMemory {
+			gc = GCSerial
+		}
+	}
+
+	maxHeap := c.TotalMemory.Value
+	if m.Heap != nil {
+		maxHeap = m.Heap.Value
+	}
+
+	n := NewNativeOverhead(gc, c.JavaVersion, zGenerational, maxHeap, c.LoadedClassCount, nmt, c.MallocArenas)
+	if m.NativeOverhead.CompressedClassSpace.Provenance == UserConfigured {
+		n.CompressedClassSpace = m.NativeOverhead.CompressedClassSpace
+	}
+
+	return n, nil
+}
+
+// compactObjectHeaders returns whether the last of -XX:+UseCompactObjectHeaders and -XX:-UseCompactObjectHeaders in
+// flags enables compact object headers.
+func compactObjectHeaders(flags string) bool {
+	enabled := false
+	for _, f := range strings.Fields(flags) {
+		switch f {
+		case "-XX:+UseCompactObjectHeaders":
+			enabled = true
+		case "-XX:-UseCompactObjectHeaders":
+			enabled = false
+		}
+	}
+	return enabled
 }
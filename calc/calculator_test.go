@@ -32,7 +32,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 
 	it("calculates metaspace", func() {
 		c := calc.Calculator{
-			HeadRoom:         0,
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
 			TotalMemory:      calc.Size{Value: calc.Gibi},
@@ -46,7 +45,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 
 	it("returns error if fixed regions are too large", func() {
 		c := calc.Calculator{
-			HeadRoom:         0,
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
 			TotalMemory:      calc.Size{Value: calc.Kibi},
@@ -54,12 +52,12 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 
 		_, err := c.Calculate("")
 		Expect(err).To(MatchError(
-			"fixed memory regions require 272286K which is greater than 1K available for allocation: -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads"))
+			"fixed memory regions require 276528K which is greater than 1K available for allocation: -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads, 4242K native overhead (GC 0, symbol tables 4242K, malloc arenas 0)"))
 	})
 
 	it("calculates head room", func() {
 		c := calc.Calculator{
-			HeadRoom:         1,
+			HeadRoom:         calc.PercentageAmount(1),
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
 			TotalMemory:      calc.Size{Value: calc.Gibi},
@@ -72,22 +70,90 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		Expect(out.Memory.HeadRoom).To(Equal(&calc.HeadRoom{Value: int64(s), Provenance: calc.Calculated}))
 	})
 
+	it("uses absolute head room", func() {
+		c := calc.Calculator{
+			HeadRoom:         calc.AbsoluteAmount(256 * calc.Mebi),
+			LoadedClassCount: 100,
+			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+			TotalMemory:      calc.Size{Value: calc.Gibi},
+		}
+
+		out, err := c.Calculate("")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out.Memory.HeadRoom).To(Equal(&calc.HeadRoom{Value: 256 * calc.Mebi, Provenance: calc.UserConfigured}))
+	})
+
+	it("defaults to no head room", func() {
+		c := calc.Calculator{
+			LoadedClassCount: 100,
+			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+			TotalMemory:      calc.Size{Value: calc.Gibi},
+		}
+
+		out, err := c.Calculate("")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out.Memory.HeadRoom).To(Equal(&calc.HeadRoom{Provenance: calc.Default}))
+	})
+
+	context("direct memory and reserved code cache amounts", func() {
+		var c calc.Calculator
+
+		it.Before(func() {
+			directMemory, codeCache := calc.PercentageAmount(5), calc.PercentageAmount(1)
+			c = calc.Calculator{
+				DirectMemory:      &directMemory,
+				LoadedClassCount:  100,
+				ReservedCodeCache: &codeCache,
+				ThreadCount:       calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:       calc.Size{Value: 2 * calc.Gibi},
+			}
+		})
+
+		it("calculates direct memory and reserved code cache from total memory", func() {
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(out.Memory.DirectMemory).To(Equal(calc.DirectMemory{Value: 107374182, Provenance: calc.Calculated}))
+			Expect(out.Memory.ReservedCodeCache).To(Equal(calc.ReservedCodeCache{Value: 21474836, Provenance: calc.Calculated}))
+		})
+
+		it("does not override sizes configured in flags", func() {
+			out, err := c.Calculate("-XX:MaxDirectMemorySize=64M -XX:ReservedCodeCacheSize=128M")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(out.Memory.DirectMemory).To(Equal(calc.DirectMemory{Value: 64 * calc.Mebi, Provenance: calc.UserConfigured}))
+			Expect(out.Memory.ReservedCodeCache).To(Equal(calc.ReservedCodeCache{Value: 128 * calc.Mebi, Provenance: calc.UserConfigured}))
+		})
+
+		it("does not scale the reserved code cache in the low profile, but keeps the JVM minimum", func() {
+			c.LowProfile = true
+			c.TotalMemory = calc.Size{Value: 128 * calc.Mebi}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(out.Memory.ReservedCodeCache).To(Equal(calc.ReservedCodeCache{Value: calc.MinCodeCacheSize, Provenance: calc.Calculated}))
+		})
+	})
+
 	it("returns error if non-heap regions are too large", func() {
 		c := calc.Calculator{
-			HeadRoom:         1,
+			GC:               calc.GCEpsilon,
+			HeadRoom:         calc.PercentageAmount(1),
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.UserConfigured},
-			TotalMemory:      calc.Size{Value: 272287 * calc.Kibi},
+			TotalMemory:      calc.Size{Value: 276627 * calc.Kibi},
 		}
 
 		_, err := c.Calculate("")
 		Expect(err).To(MatchError(
-			"non-heap memory regions require 275009K which is greater than 272287K available for allocation: 2722K headroom, -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads"))
+			"non-heap memory regions require 279295K which is greater than 276627K available for allocation: 2766K headroom, -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads, 4242K native overhead (GC 0, symbol tables 4242K, malloc arenas 0)"))
 	})
 
 	it("calculates heap", func() {
 		c := calc.Calculator{
-			HeadRoom:         0,
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
 			TotalMemory:      calc.Size{Value: calc.Gibi},
@@ -96,20 +162,20 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		out, err := c.Calculate("")
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(out.Memory.Heap).To(Equal(&calc.Heap{Value: 794920672, Provenance: calc.Calculated}))
+		Expect(out.Memory.Heap).To(Equal(&calc.Heap{Value: 779838950, Provenance: calc.Calculated}))
 	})
 
 	it("returns error of all regions are too large", func() {
 		c := calc.Calculator{
-			HeadRoom:         0,
+			GC:               calc.GCEpsilon,
 			LoadedClassCount: 100,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.UserConfigured},
-			TotalMemory:      calc.Size{Value: 272287 * calc.Kibi},
+			TotalMemory:      calc.Size{Value: 276627 * calc.Kibi},
 		}
 
 		_, err := c.Calculate("-Xmx1M")
 		Expect(err).To(MatchError(
-			"all memory regions require 273310K which is greater than 272287K available for allocation: -Xmx1M, 0 headroom, -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads"))
+			"all memory regions require 277552K which is greater than 276627K available for allocation: -Xmx1M, 0 headroom, -XX:MaxDirectMemorySize=10M, -XX:MaxMetaspaceSize=14238K, -XX:ReservedCodeCacheSize=240M, -Xss1M * 2 threads, 4242K native overhead (GC 0, symbol tables 4242K, malloc arenas 0)"))
 	})
 
 	it("returns error when calculated heap is positive but below JVM minimum of 32M", func() {
@@ -117,9 +183,10 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// AllRegionsSize > TotalMemory check) but is below MinHeapSize(32M) — only that guard catches it.
 		loadedClasses := 100
 		metaspace := calc.ClassOverhead + calc.ClassSize*int64(loadedClasses)
-		fixed := (10+240+2+31)*calc.Mebi + metaspace
+		native := calc.SymbolTableOverhead + calc.SymbolTableClassSize*int64(loadedClasses)
+		fixed := (10+240+2+31)*calc.Mebi + metaspace + native
 		c := calc.Calculator{
-			HeadRoom:         0,
+			GC:               calc.GCEpsilon,
 			LoadedClassCount: loadedClasses,
 			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.UserConfigured},
 			TotalMemory:      calc.Size{Value: fixed},
@@ -129,10 +196,92 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		Expect(err.Error()).To(ContainSubstring("less than the JVM minimum of 32M"))
 	})
 
+	it("returns error if initial heap is larger than heap", func() {
+		c := calc.Calculator{
+			LoadedClassCount: 100,
+			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+			TotalMemory:      calc.Size{Value: calc.Gibi},
+		}
+
+		_, err := c.Calculate("-Xms900M")
+		Expect(err).To(MatchError(HavePrefix("initial heap size -Xms900M is greater than the maximum heap size -Xmx")))
+	})
+
+	it("uses user configured compressed class space", func() {
+		c := calc.Calculator{
+			LoadedClassCount: 100,
+			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+			TotalMemory:      calc.Size{Value: calc.Gibi},
+		}
+
+		out, err := c.Calculate("-XX:CompressedClassSpaceSize=64M")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Memory.NativeOverhead.CompressedClassSpace).To(Equal(calc.Size{Value: 64 * calc.Mebi, Provenance: calc.UserConfigured}))
+	})
+
+	context("native overhead", func() {
+		it("selects the Serial GC for small containers", func() {
+			c := calc.Calculator{
+				LoadedClassCount: 100,
+				ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:      calc.Size{Value: calc.Gibi},
+			}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.NativeOverhead.GC).To(Equal(calc.Size{Value: calc.Gibi / 100, Provenance: calc.Calculated}))
+		})
+
+		it("selects the G1 GC for large containers", func() {
+			c := calc.Calculator{
+				JavaVersion:      21,
+				LoadedClassCount: 100,
+				ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:      calc.Size{Value: 2 * calc.Gibi},
+			}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.NativeOverhead.GC.Value).To(Equal(int64(calc.GCOverheadRatio(calc.GCG1, 21, false) * float64(2*calc.Gibi))))
+		})
+
+		it("uses the GC and heap from flags", func() {
+			c := calc.Calculator{
+				GC:               calc.GCSerial,
+				JavaVersion:      21,
+				LoadedClassCount: 100,
+				ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:      calc.Size{Value: 2 * calc.Gibi},
+			}
+
+			out, err := c.Calculate("-Xmx1G -XX:+UseZGC -XX:+ZGenerational")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.NativeOverhead.GC.Value).To(Equal(int64(calc.GCOverheadRatio(calc.GCZ, 21, true) * float64(calc.Gibi))))
+		})
+
+		it("includes Native Memory Tracking and malloc arenas", func() {
+			c := calc.Calculator{
+				LoadedClassCount:     100,
+				MallocArenas:         2,
+				NativeMemoryTracking: calc.NMTSummary,
+				ThreadCount:          calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:          calc.Size{Value: calc.Gibi},
+			}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.NativeOverhead.NMT).To(Equal(calc.Size{Value: calc.NMTSummaryOverhead, Provenance: calc.Calculated}))
+			Expect(out.Memory.NativeOverhead.MallocArenas).To(Equal(calc.Size{Value: 2 * calc.MallocArenaOverhead, Provenance: calc.Calculated}))
+
+			out, err = c.Calculate("-XX:NativeMemoryTracking=off")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.NativeOverhead.NMT.Value).To(BeZero())
+		})
+	})
+
 	context("low-profile mode", func() {
 		it("does not scale at 1G (baseline)", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				ThreadCount:      calc.DefaultThreadCountValue,
 				TotalMemory:      calc.Size{Value: calc.Gibi},
@@ -147,7 +296,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// scaling factor 0.5
 		it("scales stack, thread count and code cache at 512M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -163,7 +311,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// scaling factor 0.25
 		it("scales stack, thread count and code cache at 256M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -179,7 +326,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed stack at 384K + 512M — stack preserved, others scale
 		it("respects user-fixed stack at 512M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -195,7 +341,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed stack at 384K + 256M — stack preserved, others scale
 		it("respects user-fixed stack at 256M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -211,7 +356,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed thread count at 50 + 512M — thread count preserved, others scale
 		it("respects user-fixed thread count at 512M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.ThreadCount{Value: 50, Provenance: calc.UserConfigured},
@@ -227,7 +371,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed thread count at 50 + 256M — thread count preserved, others scale
 		it("respects user-fixed thread count at 256M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.ThreadCount{Value: 50, Provenance: calc.UserConfigured},
@@ -243,7 +386,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed code cache at 120M + 512M — code cache preserved, others scale
 		it("respects user-fixed code cache at 512M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -259,7 +401,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// user-fixed code cache at 120M + 256M — code cache preserved, stack/threads scale
 		it("respects user-fixed code cache at 256M", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -276,7 +417,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// scaling factor = 128/1024 = 0.125 → 1M*0.125 = 128K < 256K → floor kicks in
 		it("enforces minimum stack size floor", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -291,7 +431,6 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// 100M -> scaling factor ~0.097 -> 250*0.097=24 < 30, so floor kicks in
 		it("enforces minimum thread count floor", func() {
 			c := calc.Calculator{
-				HeadRoom:         0,
 				LoadedClassCount: 0,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
@@ -304,11 +443,10 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 		// heap below 32M JVM minimum → error
 		it("returns error when heap would be below JVM minimum of 32M", func() {
 			// At 64M, scaling floors: stack=256K, cache=15M, threads=30.
-			// With 3000 loaded classes: metaspace ≈ 30664K.
-			// Heap = 64M - (10M + 30664K + 15M + 256K*30) ≈ 64M - 63.2M = < 32M → error.
+			// With 1000 loaded classes: metaspace ≈ 12500K and native overhead ≈ 7193K.
+			// Heap = 64M - (10M + 12500K + 15M + 256K*30 + 7193K) ≈ 64M - 52.2M = < 32M → error.
 			c := calc.Calculator{
-				HeadRoom:         0,
-				LoadedClassCount: 3000,
+				LoadedClassCount: 1000,
 				LowProfile:       true,
 				ThreadCount:      calc.DefaultThreadCountValue,
 				TotalMemory:      calc.Size{Value: 64 * calc.Mebi},
@@ -321,4 +459,188 @@ func testCalculator(t *testing.T, context spec.G, it spec.S) {
 			Expect(err.Error()).To(ContainSubstring("-XX:ReservedCodeCacheSize"))
 		})
 	})
+
+	context("low-profile flags", func() {
+		var c calc.Calculator
+
+		it.Before(func() {
+			c = calc.Calculator{
+				LowProfile:  true,
+				ThreadCount: calc.DefaultThreadCountValue,
+				TotalMemory: calc.Size{Value: 512 * calc.Mebi},
+			}
+		})
+
+		it("does not add flags without low profile", func() {
+			c.TotalMemory = calc.Size{Value: calc.Gibi}
+			c.LowProfile = false
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).To(BeEmpty())
+		})
+
+		it("adds flags at 512M", func() {
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).To(Equal([]string{"-XX:+UseSerialGC", "-XX:CICompilerCount=2", "-Xshare:auto"}))
+		})
+
+		it("stops tiered compilation at C1 at 256M", func() {
+			c.TotalMemory = calc.Size{Value: 256 * calc.Mebi}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).To(ContainElement("-XX:TieredStopAtLevel=1"))
+		})
+
+		it("enables compact object headers for Java 25+", func() {
+			c.JavaVersion = 25
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).To(ContainElement("-XX:+UseCompactObjectHeaders"))
+		})
+
+		it("does not enable compact object headers before Java 25", func() {
+			c.JavaVersion = 24
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).NotTo(ContainElement("-XX:+UseCompactObjectHeaders"))
+		})
+
+		it("does not override configured flags", func() {
+			c.JavaVersion = 25
+			c.TotalMemory = calc.Size{Value: 256 * calc.Mebi}
+
+			out, err := c.Calculate("-XX:+UseG1GC -XX:CICompilerCount=4 -XX:TieredStopAtLevel=4 -XX:-UseCompactObjectHeaders -Xshare:off")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).To(BeEmpty())
+			Expect(out.Memory.NativeOverhead.GC.Value).To(BeNumerically(">", 0))
+		})
+
+		it("does not select a GC if the calculator has one", func() {
+			c.GC = calc.GCParallel
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.LowProfileFlags).NotTo(ContainElement("-XX:+UseSerialGC"))
+		})
+
+		it("scales the metaspace reserve", func() {
+			c.TotalMemory = calc.Size{Value: 768 * calc.Mebi}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.Metaspace).To(Equal(&calc.Metaspace{Value: 10_500_000, Provenance: calc.Calculated}))
+		})
+
+		it("enforces minimum metaspace reserve floor", func() {
+			c.TotalMemory = calc.Size{Value: 256 * calc.Mebi}
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.Metaspace).To(Equal(&calc.Metaspace{Value: calc.MinClassOverhead, Provenance: calc.Calculated}))
+		})
+	})
+
+	context("class metadata", func() {
+		var c calc.Calculator
+
+		it.Before(func() {
+			c = calc.Calculator{
+				LoadedClassCount: 10_000,
+				ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+				TotalMemory:      calc.Size{Value: calc.Gibi},
+			}
+		})
+
+		it("sizes metaspace for the Java version", func() {
+			c.JavaVersion = 8
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.ClassMetadata).To(Equal(calc.ClassMetadata{ClassOverhead: 12_000_000, ClassSize: 6_400}))
+			Expect(out.Memory.Metaspace.Value).To(Equal(int64(12_000_000 + 6_400*10_000)))
+		})
+
+		it("excludes classes in the default CDS archive", func() {
+			c.JavaVersion = 17
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.ClassMetadata.SharedClassCount).To(Equal(calc.DefaultCDSArchiveClassCount))
+			Expect(out.Memory.Metaspace.Value).To(Equal(int64(15_000_000 + 5_200*(10_000-calc.DefaultCDSArchiveClassCount))))
+		})
+
+		it("includes classes in the default CDS archive if CDS is disabled", func() {
+			c.JavaVersion = 17
+
+			out, err := c.Calculate("-Xshare:off")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.ClassMetadata.SharedClassCount).To(BeZero())
+			Expect(out.Memory.Metaspace.Value).To(Equal(int64(15_000_000 + 5_200*10_000)))
+		})
+
+		it("uses the configured class size", func() {
+			c.ClassSize = 8_000
+
+			out, err := c.Calculate("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.Metaspace.Value).To(Equal(calc.ClassOverhead + 8_000*10_000))
+		})
+
+		it("accounts for class alignment with compact object headers in metaspace", func() {
+			c.JavaVersion = 25
+
+			out, err := c.Calculate("-Xshare:off -XX:+UseCompactObjectHeaders")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Memory.Metaspace.Value).To(Equal(out.ClassMetadata.ClassOverhead + (out.ClassMetadata.ClassSize+calc.CompactHeadersClassSize)*10_000))
+			Expect(out.Memory.NativeOverhead.CompressedClassSpace.Value).To(BeZero())
+		})
+	})
+
+	context("minimum total memory", func() {
+		it("returns the smallest total memory the calculation succeeds for", func() {
+			c := calc.Calculator{
+				GC:               calc.GCEpsilon,
+				LoadedClassCount: 100,
+				ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.Default},
+			}
+
+			s, err := c.MinimumTotalMemory("", calc.Size{Value: calc.Gibi})
+			Expect(err).NotTo(HaveOccurred())
+
+			// fixed regions of 276626K and the 32M minimum heap
+			Expect(s).To(Equal(calc.Size{Value: 303 * calc.Mebi, Provenance: calc.Calculated}))
+
+			c.TotalMemory = calc.Size{Value: s.Value - calc.Mebi}
+			_, err = c.Calculate("")
+			Expect(err).To(HaveOccurred())
+		})
+
+		it("applies the low profile below the threshold", func() {
+			c := calc.Calculator{
+				GC:               calc.GCEpsilon,
+				LoadedClassCount: 100,
+				LowProfile:       true,
+				ThreadCount:      calc.DefaultThreadCountValue,
+			}
+
+			s, err := c.MinimumTotalMemory("", calc.Size{Value: calc.Gibi})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Value).To(BeNumerically("<", calc.LowProfileThreshold))
+		})
+
+		it("returns error if the calculation does not succeed for the limit", func() {
+			c := calc.Calculator{
+				LoadedClassCount: 100,
+				ThreadCount:      calc.DefaultThreadCountValue,
+			}
+
+			_, err := c.MinimumTotalMemory("", calc.Size{Value: 100 * calc.Mebi})
+			Expect(err).To(MatchError(ContainSubstring("unable to calculate memory configuration for 100M")))
+		})
+	})
 }
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var CompressedClassSpaceRE = regexp.MustCompile(fmt.Sprintf("^-XX:CompressedClassSpaceSize=(%s)$", SizePattern))
+
+func MatchCompressedClassSpace(s string) bool {
+	return CompressedClassSpaceRE.MatchString(strings.TrimSpace(s))
+}
+
+func ParseCompressedClassSpace(s string) (Size, error) {
+	g := CompressedClassSpaceRE.FindStringSubmatch(s)
+	if g == nil {
+		return Size{}, fmt.Errorf("%s does not match compressed class space pattern %s", s, CompressedClassSpaceRE.String())
+	}
+
+	z, err := ParseSize(g[1])
+	if err != nil {
+		return Size{}, fmt.Errorf("unable to parse compressed class space size\n%w", err)
+	}
+
+	return z, nil
+}
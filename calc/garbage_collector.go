@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// LargeHeapThreshold is the heap at and above which a concurrent GC is selected, as pauses of the other GCs grow
+	// with the heap.
+	LargeHeapThreshold = 16 * Gibi
+
+	// ServerClassProcessors is the number of processors below which the JVM selects the Serial GC.
+	ServerClassProcessors = 2
+
+	// SmallHeapThreshold is the heap below which the Serial GC is selected, as it has the least overhead and its pauses
+	// are short for small heaps.
+	SmallHeapThreshold = 512 * Mebi
+)
+
+// SelectGC selects a GC for a heap, a number of processors and a Java version. Like the JVM, it selects the Serial GC
+// for single processor machines and G1 otherwise, but also selects the Serial GC for small heaps. For large heaps, it
+// selects ZGC from Java 17, when ZGC is no longer experimental in any distribution.
+func SelectGC(heap int64, processors int, javaVersion int) string {
+	switch {
+	case processors < ServerClassProcessors || heap < SmallHeapThreshold:
+		return GCSerial
+	case heap >= LargeHeapThreshold && javaVersion >= 17:
+		return GCZ
+	default:
+		return GCG1
+	}
+}
+
+// ParseGC parses a GC name, such as g1 or zgc, case-insensitively.
+func ParseGC(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "epsilon":
+		return GCEpsilon, nil
+	case "g1":
+		return GCG1, nil
+	case "parallel":
+		return GCParallel, nil
+	case "serial":
+		return GCSerial, nil
+	case "shenandoah":
+		return GCShenandoah, nil
+	case "z", "zgc":
+		return GCZ, nil
+	default:
+		return "", fmt.Errorf("unrecognized GC %q", s)
+	}
+}
+
+// GCFlags returns the flags that select a GC for a Java version. Experimental GCs are unlocked and ZGC is made
+// generational on Java 21 and 22, where it is not the default.
+func GCFlags(gc string, javaVersion int) ([]string, error) {
+	switch gc {
+	case GCG1:
+		return []string{"-XX:+UseG1GC"}, nil
+	case GCParallel:
+		return []string{"-XX:+UseParallelGC"}, nil
+	case GCSerial:
+		return []string{"-XX:+UseSerialGC"}, nil
+	case GCEpsilon:
+		if javaVersion != 0 && javaVersion < 11 {
+			return nil, fmt.Errorf("the Epsilon GC requires Java 11 or later")
+		}
+		return []string{"-XX:+UnlockExperimentalVMOptions", "-XX:+UseEpsilonGC"}, nil
+	case GCShenandoah:
+		if javaVersion != 0 && javaVersion < 15 {
+			return []string{"-XX:+UnlockExperimentalVMOptions", "-XX:+UseShenandoahGC"}, nil
+		}
+		return []string{"-XX:+UseShenandoahGC"}, nil
+	case GCZ:
+		switch {
+		case javaVersion != 0 && javaVersion < 11:
+			return nil, fmt.Errorf("ZGC requires Java 11 or later")
+		case javaVersion != 0 && javaVersion < 15:
+			return []string{"-XX:+UnlockExperimentalVMOptions", "-XX:+UseZGC"}, nil
+		case javaVersion == 21 || javaVersion == 22:
+			return []string{"-XX:+UseZGC", "-XX:+ZGenerational"}, nil
+		default:
+			return []string{"-XX:+UseZGC"}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized GC %q", gc)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+func testGarbageCollector(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("select", func() {
+		it("selects Serial for a single processor", func() {
+			Expect(calc.SelectGC(4*calc.Gibi, 1, 21)).To(Equal(calc.GCSerial))
+		})
+
+		it("selects Serial for small heaps", func() {
+			Expect(calc.SelectGC(256*calc.Mebi, 4, 21)).To(Equal(calc.GCSerial))
+		})
+
+		it("selects G1", func() {
+			Expect(calc.SelectGC(4*calc.Gibi, 4, 21)).To(Equal(calc.GCG1))
+		})
+
+		it("selects ZGC for large heaps from Java 17", func() {
+			Expect(calc.SelectGC(16*calc.Gibi, 4, 17)).To(Equal(calc.GCZ))
+			Expect(calc.SelectGC(16*calc.Gibi, 4, 11)).To(Equal(calc.GCG1))
+			Expect(calc.SelectGC(16*calc.Gibi, 4, 0)).To(Equal(calc.GCG1))
+		})
+	})
+
+	context("parse", func() {
+		it("parses GC names", func() {
+			Expect(calc.ParseGC("G1")).To(Equal(calc.GCG1))
+			Expect(calc.ParseGC("parallel")).To(Equal(calc.GCParallel))
+			Expect(calc.ParseGC("serial")).To(Equal(calc.GCSerial))
+			Expect(calc.ParseGC("shenandoah")).To(Equal(calc.GCShenandoah))
+			Expect(calc.ParseGC("zgc")).To(Equal(calc.GCZ))
+		})
+
+		it("returns error for unknown GCs", func() {
+			_, err := calc.ParseGC("cms")
+			Expect(err).To(MatchError(`unrecognized GC "cms"`))
+		})
+	})
+
+	context("flags", func() {
+		it("returns flags", func() {
+			Expect(calc.GCFlags(calc.GCG1, 17)).To(Equal([]string{"-XX:+UseG1GC"}))
+			Expect(calc.GCFlags(calc.GCParallel, 17)).To(Equal([]string{"-XX:+UseParallelGC"}))
+			Expect(calc.GCFlags(calc.GCSerial, 17)).To(Equal([]string{"-XX:+UseSerialGC"}))
+			Expect(calc.GCFlags(calc.GCShenandoah, 17)).To(Equal([]string{"-XX:+UseShenandoahGC"}))
+		})
+
+		it("unlocks experimental GCs", func() {
+			Expect(calc.GCFlags(calc.GCShenandoah, 12)).To(Equal([]string{"-XX:+UnlockExperimentalVMOptions", "-XX:+UseShenandoahGC"}))
+			Expect(calc.GCFlags(calc.GCZ, 11)).To(Equal([]string{"-XX:+UnlockExperimentalVMOptions", "-XX:+UseZGC"}))
+		})
+
+		it("makes ZGC generational where it is not the default", func() {
+			Expect(calc.GCFlags(calc.GCZ, 17)).To(Equal([]string{"-XX:+UseZGC"}))
+			Expect(calc.GCFlags(calc.GCZ, 21)).To(Equal([]string{"-XX:+UseZGC", "-XX:+ZGenerational"}))
+			Expect(calc.GCFlags(calc.GCZ, 22)).To(Equal([]string{"-XX:+UseZGC", "-XX:+ZGenerational"}))
+			Expect(calc.GCFlags(calc.GCZ, 23)).To(Equal([]string{"-XX:+UseZGC"}))
+		})
+
+		it("returns error for GCs the Java version does not have", func() {
+			_, err := calc.GCFlags(calc.GCZ, 8)
+			Expect(err).To(MatchError("ZGC requires Java 11 or later"))
+
+			_, err = calc.GCFlags(calc.GCEpsilon, 8)
+			Expect(err).To(MatchError("the Epsilon GC requires Java 11 or later"))
+		})
+	})
+}
@@ -16,8 +16,24 @@
 
 package calc
 
+import (
+	"strconv"
+	"strings"
+)
+
 type HeadRoom Size
 
 func (h HeadRoom) String() string {
 	return Size(h).String()
 }
+
+// ParseHeadRoom parses the amount of headroom from the given string. As headroom was once only a percentage, a bare
+// integer is a percentage rather than a size in bytes.
+func ParseHeadRoom(s string) (Amount, error) {
+	t := strings.TrimSpace(s)
+	if _, err := strconv.Atoi(t); err == nil {
+		return ParseAmount(t + "%")
+	}
+
+	return ParseAmount(s)
+}
@@ -33,4 +33,26 @@ func testHeadroom(t *testing.T, context spec.G, it spec.S) {
 	it("formats", func() {
 		Expect(calc.HeadRoom{Value: calc.Kibi}.String()).To(Equal("1K"))
 	})
+
+	context("parse", func() {
+		it("parses integers as percentages", func() {
+			Expect(calc.ParseHeadRoom("10")).To(Equal(calc.PercentageAmount(10)))
+		})
+
+		it("parses amounts", func() {
+			Expect(calc.ParseHeadRoom("256M")).To(Equal(calc.AbsoluteAmount(256 * calc.Mebi)))
+			Expect(calc.ParseHeadRoom("max(5%, 128M)")).To(Equal(calc.Amount{
+				Function: "max",
+				Operands: []calc.Amount{calc.PercentageAmount(5), calc.AbsoluteAmount(128 * calc.Mebi)},
+			}))
+		})
+
+		it("returns error for percentages out of range", func() {
+			_, err := calc.ParseHeadRoom("-5")
+			Expect(err).To(HaveOccurred())
+
+			_, err = calc.ParseHeadRoom("150")
+			Expect(err).To(MatchError(ContainSubstring("must be between 0 and 100")))
+		})
+	})
 }
@@ -22,7 +22,10 @@ import (
 	"strings"
 )
 
-var HeapRE = regexp.MustCompile(fmt.Sprintf("^-Xmx(%s)$", SizePattern))
+var (
+	HeapRE        = regexp.MustCompile(fmt.Sprintf("^(?:-Xmx|-XX:MaxHeapSize=)(%s)$", SizePattern))
+	InitialHeapRE = regexp.MustCompile(fmt.Sprintf("^(?:-Xms|-XX:InitialHeapSize=)(%s)$", SizePattern))
+)
 
 type Heap Size
 
@@ -48,3 +51,28 @@ func ParseHeap(s string) (*Heap, error) {
 	h := Heap(z)
 	return &h, nil
 }
+
+type InitialHeap Size
+
+func (i InitialHeap) String() string {
+	return fmt.Sprintf("-Xms%s", Size(i))
+}
+
+func MatchInitialHeap(s string) bool {
+	return InitialHeapRE.MatchString(strings.TrimSpace(s))
+}
+
+func ParseInitialHeap(s string) (*InitialHeap, error) {
+	g := InitialHeapRE.FindStringSubmatch(s)
+	if g == nil {
+		return nil, fmt.Errorf("%s does not match initial heap pattern %s", s, InitialHeapRE.String())
+	}
+
+	z, err := ParseSize(g[1])
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse initial heap size\n%w", err)
+	}
+
+	i := InitialHeap(z)
+	return &i, nil
+}
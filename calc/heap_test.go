@@ -42,7 +42,28 @@ func testHeap(t *testing.T, context spec.G, it spec.S) {
 		Expect(calc.MatchHeap("-Xss1K")).To(BeFalse())
 	})
 
+	it("matches -XX:MaxHeapSize", func() {
+		Expect(calc.MatchHeap("-XX:MaxHeapSize=1K")).To(BeTrue())
+	})
+
 	it("parses", func() {
 		Expect(calc.ParseHeap("-Xmx1K")).To(Equal(&calc.Heap{Value: calc.Kibi}))
+		Expect(calc.ParseHeap("-XX:MaxHeapSize=1024")).To(Equal(&calc.Heap{Value: calc.Kibi}))
+	})
+
+	context("initial heap", func() {
+		it("formats", func() {
+			Expect(calc.InitialHeap{Value: calc.Kibi}.String()).To(Equal("-Xms1K"))
+		})
+
+		it("matches -Xms and -XX:InitialHeapSize", func() {
+			Expect(calc.MatchInitialHeap("-Xms1K")).To(BeTrue())
+			Expect(calc.MatchInitialHeap("-XX:InitialHeapSize=1K")).To(BeTrue())
+			Expect(calc.MatchInitialHeap("-Xmx1K")).To(BeFalse())
+		})
+
+		it("parses", func() {
+			Expect(calc.ParseInitialHeap("-XX:InitialHeapSize=1K")).To(Equal(&calc.InitialHeap{Value: calc.Kibi}))
+		})
 	})
 }
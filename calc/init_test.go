@@ -25,12 +25,18 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("jvm-vendors/calc", spec.Report(report.Terminal{}))
+	suite("Amount", testAmount)
 	suite("Calculator", testCalculator)
+	suite("CompressedClassSpace", testCompressedClassSpace)
 	suite("DirectMemory", testDirectMemory)
+	suite("GarbageCollector", testGarbageCollector)
 	suite("Headroom", testHeadroom)
 	suite("Heap", testHeap)
 	suite("Metaspace", testMetaspace)
 	suite("MemoryRegions", testMemoryRegions)
+	suite("NativeOverhead", testNativeOverhead)
+	suite("RAMPercentage", testRAMPercentage)
+	suite("Report", testReport)
 	suite("ReservedCodeCache", testReservedCodeCache)
 	suite("Size", testSize)
 	suite("Stack", testStack)
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+)
+
+const (
+	// LowProfileCICompilerCount caps the JIT compiler threads, each of which allocates its own arena while compiling.
+	LowProfileCICompilerCount = 2
+
+	// C1OnlyThreshold is the total memory below which the low profile stops tiered compilation at C1. C1 code is
+	// slower, but C1 needs a fraction of the code cache and compiler memory of C2.
+	C1OnlyThreshold = 384 * Mebi
+
+	// MinClassOverhead is the lowest the low profile scales the metaspace reserved for the JVM's own metadata to.
+	MinClassOverhead = int64(7_000_000)
+)
+
+var GCFlagRE = regexp.MustCompile(`^-XX:\+Use\w+GC$`)
+
+// lowProfileFlags returns the flags of the low memory profile that flags does not configure already. The Serial GC is
+// only selected if neither flags nor the calculator select a GC.
+func (c *Calculator) lowProfileFlags(flags string) ([]string, error) {
+	p, err := shellwords.Parse(flags)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse flags\n%w", err)
+	}
+
+	configured := func(prefixes ...string) bool {
+		return slices.ContainsFunc(p, func(f string) bool {
+			return slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(f, prefix) })
+		})
+	}
+
+	var s []string
+
+	if c.GC == "" && !slices.ContainsFunc(p, GCFlagRE.MatchString) {
+		s = append(s, "-XX:+UseSerialGC")
+	}
+
+	if !configured("-XX:CICompilerCount=") {
+		s = append(s, fmt.Sprintf("-XX:CICompilerCount=%d", LowProfileCICompilerCount))
+	}
+
+	if c.TotalMemory.Value < C1OnlyThreshold && !configured("-XX:TieredStopAtLevel=", "-XX:-TieredCompilation") {
+		s = append(s, "-XX:TieredStopAtLevel=1")
+	}
+
+	if c.JavaVersion >= 25 && !configured("-XX:+UseCompactObjectHeaders", "-XX:-UseCompactObjectHeaders") {
+		s = append(s, "-XX:+UseCompactObjectHeaders")
+	}
+
+	if !configured("-Xshare:") {
+		s = append(s, "-Xshare:auto")
+	}
+
+	return s, nil
+}
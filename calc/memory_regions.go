@@ -23,17 +23,29 @@ import (
 	"github.com/mattn/go-shellwords"
 )
 
+// Region is a single memory region of a calculation, with the flag that configures it, if any.
+type Region struct {
+	Name       string     `json:"name"`
+	Flag       string     `json:"flag,omitempty"`
+	Size       int64      `json:"size"`
+	Provenance Provenance `json:"provenance"`
+}
+
 type MemoryRegions struct {
 	DirectMemory      DirectMemory
 	HeadRoom          *HeadRoom
 	Heap              *Heap
+	InitialHeap       *InitialHeap
 	Metaspace         *Metaspace
+	NativeOverhead    NativeOverhead
 	ReservedCodeCache ReservedCodeCache
 	Stack             Stack
 	ScalingFactor     float64
 }
 
-func NewMemoryRegionsFromFlags(flags string) (MemoryRegions, error) {
+// NewMemoryRegionsFromFlags creates memory regions from the flags the user has configured. Percentage-based flags are
+// converted to absolute regions against -XX:MaxRAM if it is set, otherwise against totalMemory.
+func NewMemoryRegionsFromFlags(flags string, totalMemory Size) (MemoryRegions, error) {
 	m := MemoryRegions{
 		DirectMemory:      DefaultDirectMemory,
 		ReservedCodeCache: DefaultReservedCodeCache,
@@ -46,6 +58,11 @@ func NewMemoryRegionsFromFlags(flags string) (MemoryRegions, error) {
 		return MemoryRegions{}, fmt.Errorf("unable to parse flags\n%w", err)
 	}
 
+	var (
+		maxRAM                                 *Size
+		maxRAMPercentage, initialRAMPercentage *float64
+	)
+
 	for _, f := range p {
 		switch {
 		case MatchDirectMemory(f):
@@ -78,9 +95,52 @@ func NewMemoryRegionsFromFlags(flags string) (MemoryRegions, error) {
 				return MemoryRegions{}, fmt.Errorf("unable to parse stack\n%w", err)
 			}
 			m.Stack.Provenance = UserConfigured
+		case MatchInitialHeap(f):
+			m.InitialHeap, err = ParseInitialHeap(f)
+			if err != nil {
+				return MemoryRegions{}, fmt.Errorf("unable to parse initial heap\n%w", err)
+			}
+			m.InitialHeap.Provenance = UserConfigured
+		case MatchCompressedClassSpace(f):
+			m.NativeOverhead.CompressedClassSpace, err = ParseCompressedClassSpace(f)
+			if err != nil {
+				return MemoryRegions{}, fmt.Errorf("unable to parse compressed class space\n%w", err)
+			}
+			m.NativeOverhead.CompressedClassSpace.Provenance = UserConfigured
+		case MatchMaxRAM(f):
+			s, err := ParseMaxRAM(f)
+			if err != nil {
+				return MemoryRegions{}, fmt.Errorf("unable to parse max RAM\n%w", err)
+			}
+			maxRAM = &s
+		case MatchMaxRAMPercentage(f):
+			v, err := ParseMaxRAMPercentage(f)
+			if err != nil {
+				return MemoryRegions{}, fmt.Errorf("unable to parse max RAM percentage\n%w", err)
+			}
+			maxRAMPercentage = &v
+		case MatchInitialRAMPercentage(f):
+			v, err := ParseInitialRAMPercentage(f)
+			if err != nil {
+				return MemoryRegions{}, fmt.Errorf("unable to parse initial RAM percentage\n%w", err)
+			}
+			initialRAMPercentage = &v
 		}
 	}
 
+	ram := totalMemory.Value
+	if maxRAM != nil {
+		ram = maxRAM.Value
+	}
+
+	// as in the JVM, explicit sizes take precedence over percentages
+	if m.Heap == nil && maxRAMPercentage != nil {
+		m.Heap = &Heap{Value: int64(*maxRAMPercentage / 100 * float64(ram)), Provenance: UserConfigured}
+	}
+	if m.InitialHeap == nil && initialRAMPercentage != nil {
+		m.InitialHeap = &InitialHeap{Value: int64(*initialRAMPercentage / 100 * float64(ram)), Provenance: UserConfigured}
+	}
+
 	return m, nil
 }
 
@@ -117,7 +177,7 @@ func (m MemoryRegions) FixedRegionsSize(threadCount int) (Size, error) {
 	}
 
 	return Size{
-		Value:      m.DirectMemory.Value + m.Metaspace.Value + m.ReservedCodeCache.Value + (m.Stack.Value * int64(threadCount)),
+		Value:      m.DirectMemory.Value + m.Metaspace.Value + m.ReservedCodeCache.Value + (m.Stack.Value * int64(threadCount)) + m.NativeOverhead.Size().Value,
 		Provenance: Calculated,
 	}, nil
 }
@@ -131,6 +191,9 @@ func (m MemoryRegions) FixedRegionsString(threadCount int) string {
 	}
 	s = append(s, m.ReservedCodeCache.String())
 	s = append(s, fmt.Sprintf("%s * %d threads", m.Stack.String(), threadCount))
+	if m.NativeOverhead.Size().Value > 0 {
+		s = append(s, m.NativeOverhead.String())
+	}
 
 	return strings.Join(s, ", ")
 }
@@ -161,3 +224,60 @@ func (m MemoryRegions) NonHeapRegionsString(threadCount int) string {
 
 	return strings.Join(s, ", ")
 }
+
+// CalculatedFlags returns the flags for the regions that have not been configured by the user.
+func (m MemoryRegions) CalculatedFlags() []string {
+	var s []string
+
+	if m.DirectMemory.Provenance != UserConfigured {
+		s = append(s, m.DirectMemory.String())
+	}
+	if m.Heap != nil && m.Heap.Provenance != UserConfigured {
+		s = append(s, m.Heap.String())
+	}
+	if m.Metaspace != nil && m.Metaspace.Provenance != UserConfigured {
+		s = append(s, m.Metaspace.String())
+	}
+	if m.ReservedCodeCache.Provenance != UserConfigured {
+		s = append(s, m.ReservedCodeCache.String())
+	}
+	if m.Stack.Provenance != UserConfigured {
+		s = append(s, m.Stack.String())
+	}
+
+	return s
+}
+
+// Regions returns a breakdown of the memory regions, with the native overhead split into its parts. Regions that have
+// not been calculated yet are omitted.
+func (m MemoryRegions) Regions(threadCount int) []Region {
+	var r []Region
+
+	if m.Heap != nil {
+		r = append(r, Region{Name: "heap", Flag: m.Heap.String(), Size: m.Heap.Value, Provenance: m.Heap.Provenance})
+	}
+	if m.HeadRoom != nil {
+		r = append(r, Region{Name: "head_room", Size: m.HeadRoom.Value, Provenance: m.HeadRoom.Provenance})
+	}
+	r = append(r, Region{Name: "direct_memory", Flag: m.DirectMemory.String(), Size: m.DirectMemory.Value, Provenance: m.DirectMemory.Provenance})
+	if m.Metaspace != nil {
+		r = append(r, Region{Name: "metaspace", Flag: m.Metaspace.String(), Size: m.Metaspace.Value, Provenance: m.Metaspace.Provenance})
+	}
+	r = append(r, Region{Name: "reserved_code_cache", Flag: m.ReservedCodeCache.String(), Size: m.ReservedCodeCache.Value, Provenance: m.ReservedCodeCache.Provenance})
+	r = append(r, Region{Name: "stack", Flag: m.Stack.String(), Size: m.Stack.Value * int64(threadCount), Provenance: m.Stack.Provenance})
+
+	n := m.NativeOverhead
+	var ccs string
+	if n.CompressedClassSpace.Provenance == UserConfigured {
+		ccs = fmt.Sprintf("-XX:CompressedClassSpaceSize=%s", n.CompressedClassSpace)
+	}
+	r = append(r,
+		Region{Name: "compressed_class_space", Flag: ccs, Size: n.CompressedClassSpace.Value, Provenance: n.CompressedClassSpace.Provenance},
+		Region{Name: "gc", Size: n.GC.Value, Provenance: n.GC.Provenance},
+		Region{Name: "symbol_tables", Size: n.SymbolTables.Value, Provenance: n.SymbolTables.Provenance},
+		Region{Name: "nmt", Size: n.NMT.Value, Provenance: n.NMT.Provenance},
+		Region{Name: "malloc_arenas", Size: n.MallocArenas.Value, Provenance: n.MallocArenas.Provenance},
+	)
+
+	return r
+}
@@ -34,7 +34,7 @@ func testMemoryRegions(t *testing.T, context spec.G, it spec.S) {
 
 	context("NewMemoryRegionsFromFlags", func() {
 		it("defaults", func() {
-			Expect(calc.NewMemoryRegionsFromFlags("")).To(Equal(calc.MemoryRegions{
+			Expect(calc.NewMemoryRegionsFromFlags("", calc.Size{Value: calc.Gibi})).To(Equal(calc.MemoryRegions{
 				DirectMemory:      calc.DefaultDirectMemory,
 				ReservedCodeCache: calc.DefaultReservedCodeCache,
 				Stack:             calc.DefaultStack,
@@ -43,7 +43,7 @@ func testMemoryRegions(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("with flags", func() {
-			Expect(calc.NewMemoryRegionsFromFlags("-XX:MaxDirectMemorySize=1K -Xmx1K -XX:MaxMetaspaceSize=1K -XX:ReservedCodeCacheSize=1K -Xss1K")).
+			Expect(calc.NewMemoryRegionsFromFlags("-XX:MaxDirectMemorySize=1K -Xmx1K -XX:MaxMetaspaceSize=1K -XX:ReservedCodeCacheSize=1K -Xss1K", calc.Size{Value: calc.Gibi})).
 				To(Equal(calc.MemoryRegions{
 					DirectMemory:      calc.DirectMemory{Value: calc.Kibi, Provenance: calc.UserConfigured},
 					Heap:              &calc.Heap{Value: calc.Kibi, Provenance: calc.UserConfigured},
@@ -53,6 +53,43 @@ func testMemoryRegions(t *testing.T, context spec.G, it spec.S) {
 					ScalingFactor:     1.0,
 				}))
 		})
+
+		it("with -XX flags", func() {
+			Expect(calc.NewMemoryRegionsFromFlags("-XX:MaxHeapSize=2K -XX:InitialHeapSize=1K -XX:ThreadStackSize=512 -XX:CompressedClassSpaceSize=1M", calc.Size{Value: calc.Gibi})).
+				To(Equal(calc.MemoryRegions{
+					DirectMemory:      calc.DefaultDirectMemory,
+					Heap:              &calc.Heap{Value: 2 * calc.Kibi, Provenance: calc.UserConfigured},
+					InitialHeap:       &calc.InitialHeap{Value: calc.Kibi, Provenance: calc.UserConfigured},
+					NativeOverhead:    calc.NativeOverhead{CompressedClassSpace: calc.Size{Value: calc.Mebi, Provenance: calc.UserConfigured}},
+					ReservedCodeCache: calc.DefaultReservedCodeCache,
+					Stack:             calc.Stack{Value: 512 * calc.Kibi, Provenance: calc.UserConfigured},
+					ScalingFactor:     1.0,
+				}))
+		})
+
+		it("converts percentages against total memory", func() {
+			m, err := calc.NewMemoryRegionsFromFlags("-XX:MaxRAMPercentage=75.0 -XX:InitialRAMPercentage=25", calc.Size{Value: calc.Gibi})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Heap).To(Equal(&calc.Heap{Value: 768 * calc.Mebi, Provenance: calc.UserConfigured}))
+			Expect(m.InitialHeap).To(Equal(&calc.InitialHeap{Value: 256 * calc.Mebi, Provenance: calc.UserConfigured}))
+		})
+
+		it("converts percentages against -XX:MaxRAM", func() {
+			m, err := calc.NewMemoryRegionsFromFlags("-XX:MaxRAMPercentage=50 -XX:MaxRAM=512M", calc.Size{Value: calc.Gibi})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Heap).To(Equal(&calc.Heap{Value: 256 * calc.Mebi, Provenance: calc.UserConfigured}))
+		})
+
+		it("prefers explicit sizes over percentages", func() {
+			m, err := calc.NewMemoryRegionsFromFlags("-Xmx100M -XX:MaxRAMPercentage=75", calc.Size{Value: calc.Gibi})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Heap).To(Equal(&calc.Heap{Value: 100 * calc.Mebi, Provenance: calc.UserConfigured}))
+		})
+
+		it("returns error for percentages over 100", func() {
+			_, err := calc.NewMemoryRegionsFromFlags("-XX:MaxRAMPercentage=150", calc.Size{Value: calc.Gibi})
+			Expect(err).To(MatchError(ContainSubstring("percentage 150 must not be greater than 100")))
+		})
 	})
 
 	context("all regions", func() {
@@ -132,4 +169,60 @@ func testMemoryRegions(t *testing.T, context spec.G, it spec.S) {
 				"1K headroom, -XX:MaxDirectMemorySize=1K, -XX:MaxMetaspaceSize=1K, -XX:ReservedCodeCacheSize=1K, -Xss1K * 2 threads"))
 		})
 	})
+
+	context("calculated flags", func() {
+		it("omits user configured regions", func() {
+			m = calc.MemoryRegions{
+				DirectMemory:      calc.DirectMemory{Value: calc.Kibi, Provenance: calc.UserConfigured},
+				Heap:              &calc.Heap{Value: calc.Kibi, Provenance: calc.Calculated},
+				Metaspace:         &calc.Metaspace{Value: calc.Kibi, Provenance: calc.UserConfigured},
+				ReservedCodeCache: calc.ReservedCodeCache{Value: calc.Kibi, Provenance: calc.Default},
+				Stack:             calc.Stack{Value: calc.Kibi, Provenance: calc.Calculated},
+			}
+
+			Expect(m.CalculatedFlags()).To(Equal([]string{"-Xmx1K", "-XX:ReservedCodeCacheSize=1K", "-Xss1K"}))
+		})
+	})
+
+	context("regions", func() {
+		it("returns a breakdown of all regions", func() {
+			m = calc.MemoryRegions{
+				DirectMemory: calc.DirectMemory{Value: calc.Kibi, Provenance: calc.Default},
+				HeadRoom:     &calc.HeadRoom{Value: calc.Kibi, Provenance: calc.Calculated},
+				Heap:         &calc.Heap{Value: calc.Kibi, Provenance: calc.UserConfigured},
+				Metaspace:    &calc.Metaspace{Value: calc.Kibi, Provenance: calc.Calculated},
+				NativeOverhead: calc.NativeOverhead{
+					CompressedClassSpace: calc.Size{Value: calc.Mebi, Provenance: calc.UserConfigured},
+					GC:                   calc.Size{Value: calc.Kibi, Provenance: calc.Calculated},
+					SymbolTables:         calc.Size{Value: calc.Kibi, Provenance: calc.Calculated},
+					MallocArenas:         calc.Size{Value: calc.Kibi, Provenance: calc.Calculated},
+				},
+				ReservedCodeCache: calc.ReservedCodeCache{Value: calc.Kibi, Provenance: calc.Default},
+				Stack:             calc.Stack{Value: calc.Kibi, Provenance: calc.Default},
+			}
+
+			Expect(m.Regions(2)).To(Equal([]calc.Region{
+				{Name: "heap", Flag: "-Xmx1K", Size: calc.Kibi, Provenance: calc.UserConfigured},
+				{Name: "head_room", Size: calc.Kibi, Provenance: calc.Calculated},
+				{Name: "direct_memory", Flag: "-XX:MaxDirectMemorySize=1K", Size: calc.Kibi, Provenance: calc.Default},
+				{Name: "metaspace", Flag: "-XX:MaxMetaspaceSize=1K", Size: calc.Kibi, Provenance: calc.Calculated},
+				{Name: "reserved_code_cache", Flag: "-XX:ReservedCodeCacheSize=1K", Size: calc.Kibi, Provenance: calc.Default},
+				{Name: "stack", Flag: "-Xss1K", Size: 2 * calc.Kibi, Provenance: calc.Default},
+				{Name: "compressed_class_space", Flag: "-XX:CompressedClassSpaceSize=1M", Size: calc.Mebi, Provenance: calc.UserConfigured},
+				{Name: "gc", Size: calc.Kibi, Provenance: calc.Calculated},
+				{Name: "symbol_tables", Size: calc.Kibi, Provenance: calc.Calculated},
+				{Name: "nmt", Size: 0, Provenance: calc.Unknown},
+				{Name: "malloc_arenas", Size: calc.Kibi, Provenance: calc.Calculated},
+			}))
+		})
+
+		it("omits regions that have not been calculated", func() {
+			names := []string{}
+			for _, r := range (calc.MemoryRegions{}).Regions(2) {
+				names = append(names, r.Name)
+			}
+
+			Expect(names).NotTo(ContainElements("heap", "head_room", "metaspace"))
+		})
+	})
 }
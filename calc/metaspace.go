@@ -22,8 +22,52 @@ import (
 	"strings"
 )
 
+const (
+	// DefaultCDSArchiveClassCount is the number of JDK classes in the default CDS archive of Java 12 and later. The
+	// metadata of these classes is mapped from the archive rather than allocated in metaspace.
+	DefaultCDSArchiveClassCount = 1_300
+
+	// CompactHeadersClassSize is the additional metaspace used per loaded class with compact object headers, which
+	// align each class in the compressed class space to 1K so that it can be addressed with a 22-bit class pointer.
+	// Part of the alignment gap is reused for other metadata.
+	CompactHeadersClassSize = int64(256)
+)
+
 var MetaspaceRE = regexp.MustCompile(fmt.Sprintf("^-XX:MaxMetaspaceSize=(%s)$", SizePattern))
 
+// ClassMetadata is the metaspace the JVM uses for its own metadata and for each loaded class.
+type ClassMetadata struct {
+	ClassOverhead    int64 `json:"class_overhead"`
+	ClassSize        int64 `json:"class_size"`
+	SharedClassCount int   `json:"shared_class_count"`
+}
+
+// ClassMetadataSizes are the class metadata sizes calibrated for each Java version, ordered by the first version they
+// apply to. They are calibrated against all of metaspace, the compressed class space included, as -XX:MaxMetaspaceSize
+// bounds both. An unknown version uses the original calibration, which was made against Java 11. Elastic metaspace in
+// Java 16 reduced the per-class fragmentation, while the JDK's own metadata, such as that of lambda forms, has grown.
+var ClassMetadataSizes = []struct {
+	JavaVersion   int
+	ClassMetadata ClassMetadata
+}{
+	{0, ClassMetadata{ClassOverhead: ClassOverhead, ClassSize: ClassSize}},
+	{8, ClassMetadata{ClassOverhead: 12_000_000, ClassSize: 6_400}},
+	{11, ClassMetadata{ClassOverhead: 14_000_000, ClassSize: 5_800}},
+	{17, ClassMetadata{ClassOverhead: 15_000_000, ClassSize: 5_200}},
+	{21, ClassMetadata{ClassOverhead: 16_000_000, ClassSize: 5_000}},
+}
+
+// ClassMetadataFor returns the calibrated class metadata sizes of a Java version.
+func ClassMetadataFor(javaVersion int) ClassMetadata {
+	m := ClassMetadataSizes[0].ClassMetadata
+	for _, s := range ClassMetadataSizes {
+		if javaVersion >= s.JavaVersion {
+			m = s.ClassMetadata
+		}
+	}
+	return m
+}
+
 type Metaspace Size
 
 func (m Metaspace) String() string {
@@ -45,4 +45,20 @@ func testMetaspace(t *testing.T, context spec.G, it spec.S) {
 	it("parses", func() {
 		Expect(calc.ParseMetaspace("-XX:MaxMetaspaceSize=1K")).To(Equal(&calc.Metaspace{Value: calc.Kibi}))
 	})
+
+	context("class metadata", func() {
+		it("uses the original calibration for an unknown version", func() {
+			Expect(calc.ClassMetadataFor(0)).To(Equal(calc.ClassMetadata{ClassOverhead: calc.ClassOverhead, ClassSize: calc.ClassSize}))
+		})
+
+		it("uses the calibration of the version", func() {
+			Expect(calc.ClassMetadataFor(8)).To(Equal(calc.ClassMetadata{ClassOverhead: 12_000_000, ClassSize: 6_400}))
+			Expect(calc.ClassMetadataFor(17)).To(Equal(calc.ClassMetadata{ClassOverhead: 15_000_000, ClassSize: 5_200}))
+		})
+
+		it("uses the calibration of the closest earlier version", func() {
+			Expect(calc.ClassMetadataFor(9)).To(Equal(calc.ClassMetadataFor(8)))
+			Expect(calc.ClassMetadataFor(25)).To(Equal(calc.ClassMetadataFor(21)))
+		})
+	})
 }
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	GCEpsilon    = "Epsilon"
+	GCG1         = "G1"
+	GCParallel   = "Parallel"
+	GCSerial     = "Serial"
+	GCShenandoah = "Shenandoah"
+	GCZ          = "Z"
+
+	NMTOff     = "off"
+	NMTSummary = "summary"
+	NMTDetail  = "detail"
+
+	// SymbolTableClassSize and SymbolTableOverhead estimate the symbol and string tables, which grow with the number
+	// of loaded classes.
+	SymbolTableClassSize = int64(1_500)
+	SymbolTableOverhead  = 4 * Mebi
+
+	// NMTSummaryOverhead and NMTDetailOverhead are the malloc headers and call site tables of Native Memory Tracking.
+	NMTSummaryOverhead = 2 * Mebi
+	NMTDetailOverhead  = 12 * Mebi
+
+	// MallocArenaOverhead is the memory each glibc malloc arena retains after it has been freed.
+	MallocArenaOverhead = 4 * Mebi
+
+	// DefaultMallocArenas is the $MALLOC_ARENA_MAX contributed by the JRE layer.
+	DefaultMallocArenas = 2
+
+	// ServerClassMemory is the memory below which the JVM selects the Serial GC if none is configured.
+	ServerClassMemory = 1792 * Mebi
+)
+
+// NativeOverhead is memory the JVM uses outside the regions that can be sized with flags. The compressed class space
+// is bounded by -XX:MaxMetaspaceSize along with the rest of metaspace, so it is only counted separately when it is
+// configured with -XX:CompressedClassSpaceSize.
+type NativeOverhead struct {
+	CompressedClassSpace Size
+	GC                   Size
+	SymbolTables         Size
+	NMT                  Size
+	MallocArenas         Size
+}
+
+// NewNativeOverhead estimates the native overhead of a JVM. The GC overhead is a fraction of maxHeap, which is an
+// upper bound of the heap when it has not been calculated yet.
+func NewNativeOverhead(gc string, javaVersion int, zGenerational bool, maxHeap int64, loadedClassCount int,
+	nmt string, mallocArenas int) NativeOverhead {
+
+	n := NativeOverhead{
+		GC:           Size{Value: int64(GCOverheadRatio(gc, javaVersion, zGenerational) * float64(maxHeap)), Provenance: Calculated},
+		SymbolTables: Size{Value: SymbolTableOverhead + (SymbolTableClassSize * int64(loadedClassCount)), Provenance: Calculated},
+		MallocArenas: Size{Value: MallocArenaOverhead * int64(mallocArenas), Provenance: Calculated},
+	}
+
+	switch nmt {
+	case NMTSummary:
+		n.NMT = Size{Value: NMTSummaryOverhead, Provenance: Calculated}
+	case NMTDetail:
+		n.NMT = Size{Value: NMTDetailOverhead, Provenance: Calculated}
+	}
+
+	return n
+}
+
+// GCOverheadRatio returns the fraction of the heap used by a GC's native data structures, such as card tables, mark
+// bitmaps and remembered sets. An unknown Java version is treated as the oldest, most expensive, one. ZGC's
+// multi-mapping of the heap is not counted, as the mappings share the same physical memory.
+func GCOverheadRatio(gc string, javaVersion int, zGenerational bool) float64 {
+	switch gc {
+	case GCEpsilon:
+		return 0
+	case GCSerial:
+		return 0.01
+	case GCParallel, GCShenandoah:
+		return 0.03
+	case GCZ:
+		if zGenerational {
+			return 0.05
+		}
+		return 0.03
+	default:
+		// G1 remembered sets were made considerably smaller in Java 18 and one of the two mark bitmaps was removed in
+		// Java 20
+		switch {
+		case javaVersion >= 20:
+			return 0.04
+		case javaVersion >= 18:
+			return 0.05
+		default:
+			return 0.08
+		}
+	}
+}
+
+func (n NativeOverhead) Size() Size {
+	return Size{
+		Value:      n.CompressedClassSpace.Value + n.GC.Value + n.SymbolTables.Value + n.NMT.Value + n.MallocArenas.Value,
+		Provenance: Calculated,
+	}
+}
+
+func (n NativeOverhead) String() string {
+	var s []string
+
+	if n.CompressedClassSpace.Value > 0 {
+		s = append(s, fmt.Sprintf("compressed class space %s", n.CompressedClassSpace))
+	}
+	s = append(s, fmt.Sprintf("GC %s", n.GC))
+	s = append(s, fmt.Sprintf("symbol tables %s", n.SymbolTables))
+	if n.NMT.Value > 0 {
+		s = append(s, fmt.Sprintf("NMT %s", n.NMT))
+	}
+	s = append(s, fmt.Sprintf("malloc arenas %s", n.MallocArenas))
+
+	return fmt.Sprintf("%s native overhead (%s)", n.Size(), strings.Join(s, ", "))
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+func testNativeOverhead(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("estimates native overhead", func() {
+		n := calc.NewNativeOverhead(calc.GCParallel, 21, false, 100*calc.Mebi, 1_000, calc.NMTDetail, 2)
+
+		Expect(n.CompressedClassSpace.Value).To(BeZero())
+		Expect(n.GC.Value).To(Equal(3 * calc.Mebi))
+		Expect(n.SymbolTables.Value).To(Equal(calc.SymbolTableOverhead + 1_000*calc.SymbolTableClassSize))
+		Expect(n.NMT.Value).To(Equal(calc.NMTDetailOverhead))
+		Expect(n.MallocArenas.Value).To(Equal(2 * calc.MallocArenaOverhead))
+		Expect(n.Size()).To(Equal(calc.Size{
+			Value:      n.CompressedClassSpace.Value + n.GC.Value + n.SymbolTables.Value + n.NMT.Value + n.MallocArenas.Value,
+			Provenance: calc.Calculated,
+		}))
+	})
+
+	it("returns string", func() {
+		n := calc.NativeOverhead{
+			CompressedClassSpace: calc.Size{Value: calc.Mebi},
+			GC:                   calc.Size{Value: 2 * calc.Mebi},
+			SymbolTables:         calc.Size{Value: 3 * calc.Mebi},
+			NMT:                  calc.Size{Value: 4 * calc.Mebi},
+			MallocArenas:         calc.Size{Value: 5 * calc.Mebi},
+		}
+
+		Expect(n.String()).To(Equal(
+			"15M native overhead (compressed class space 1M, GC 2M, symbol tables 3M, NMT 4M, malloc arenas 5M)"))
+	})
+
+	context("GC overhead", func() {
+		it("depends on the G1 version", func() {
+			Expect(calc.GCOverheadRatio(calc.GCG1, 0, false)).To(Equal(0.08))
+			Expect(calc.GCOverheadRatio(calc.GCG1, 17, false)).To(Equal(0.08))
+			Expect(calc.GCOverheadRatio(calc.GCG1, 18, false)).To(Equal(0.05))
+			Expect(calc.GCOverheadRatio(calc.GCG1, 21, false)).To(Equal(0.04))
+		})
+
+		it("depends on ZGC being generational", func() {
+			Expect(calc.GCOverheadRatio(calc.GCZ, 21, false)).To(Equal(0.03))
+			Expect(calc.GCOverheadRatio(calc.GCZ, 21, true)).To(Equal(0.05))
+		})
+
+		it("is the smallest for Serial and Epsilon", func() {
+			Expect(calc.GCOverheadRatio(calc.GCSerial, 21, false)).To(Equal(0.01))
+			Expect(calc.GCOverheadRatio(calc.GCEpsilon, 21, false)).To(BeZero())
+		})
+	})
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	MaxRAMRE               = regexp.MustCompile(fmt.Sprintf("^-XX:MaxRAM=(%s)$", SizePattern))
+	MaxRAMPercentageRE     = regexp.MustCompile(`^-XX:MaxRAMPercentage=(\d+(?:\.\d*)?)$`)
+	InitialRAMPercentageRE = regexp.MustCompile(`^-XX:InitialRAMPercentage=(\d+(?:\.\d*)?)$`)
+)
+
+func MatchMaxRAM(s string) bool {
+	return MaxRAMRE.MatchString(strings.TrimSpace(s))
+}
+
+// ParseMaxRAM parses -XX:MaxRAM, the amount of memory the JVM sizes its heap against.
+func ParseMaxRAM(s string) (Size, error) {
+	g := MaxRAMRE.FindStringSubmatch(s)
+	if g == nil {
+		return Size{}, fmt.Errorf("%s does not match max RAM pattern %s", s, MaxRAMRE.String())
+	}
+
+	z, err := ParseSize(g[1])
+	if err != nil {
+		return Size{}, fmt.Errorf("unable to parse max RAM size\n%w", err)
+	}
+
+	return z, nil
+}
+
+func MatchMaxRAMPercentage(s string) bool {
+	return MaxRAMPercentageRE.MatchString(strings.TrimSpace(s))
+}
+
+func ParseMaxRAMPercentage(s string) (float64, error) {
+	return parsePercentage(MaxRAMPercentageRE, s)
+}
+
+func MatchInitialRAMPercentage(s string) bool {
+	return InitialRAMPercentageRE.MatchString(strings.TrimSpace(s))
+}
+
+func ParseInitialRAMPercentage(s string) (float64, error) {
+	return parsePercentage(InitialRAMPercentageRE, s)
+}
+
+func parsePercentage(re *regexp.Regexp, s string) (float64, error) {
+	g := re.FindStringSubmatch(s)
+	if g == nil {
+		return 0, fmt.Errorf("%s does not match percentage pattern %s", s, re.String())
+	}
+
+	p, err := strconv.ParseFloat(g[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse percentage %s\n%w", g[1], err)
+	}
+
+	if p > 100 {
+		return 0, fmt.Errorf("percentage %s must not be greater than 100", g[1])
+	}
+
+	return p, nil
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+func testRAMPercentage(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("matches -XX:MaxRAM", func() {
+		Expect(calc.MatchMaxRAM("-XX:MaxRAM=1G")).To(BeTrue())
+		Expect(calc.MatchMaxRAM("-XX:MaxRAMPercentage=1")).To(BeFalse())
+	})
+
+	it("parses -XX:MaxRAM", func() {
+		Expect(calc.ParseMaxRAM("-XX:MaxRAM=1G")).To(Equal(calc.Size{Value: calc.Gibi}))
+	})
+
+	it("matches percentages", func() {
+		Expect(calc.MatchMaxRAMPercentage("-XX:MaxRAMPercentage=75.0")).To(BeTrue())
+		Expect(calc.MatchInitialRAMPercentage("-XX:InitialRAMPercentage=25")).To(BeTrue())
+		Expect(calc.MatchMaxRAMPercentage("-XX:MaxRAMPercentage=")).To(BeFalse())
+	})
+
+	it("parses percentages", func() {
+		Expect(calc.ParseMaxRAMPercentage("-XX:MaxRAMPercentage=75.5")).To(Equal(75.5))
+		Expect(calc.ParseInitialRAMPercentage("-XX:InitialRAMPercentage=25")).To(Equal(25.0))
+	})
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc
+
+// Report is a machine-readable summary of a calculation.
+type Report struct {
+	TotalMemory           int64         `json:"total_memory"`
+	HeadRoom              Amount        `json:"head_room"`
+	LoadedClassCount      int           `json:"loaded_class_count"`
+	ClassMetadata         ClassMetadata `json:"class_metadata"`
+	ThreadCount           int           `json:"thread_count"`
+	ThreadCountProvenance Provenance    `json:"thread_count_provenance"`
+	LowProfile            bool          `json:"low_profile"`
+	LowProfileFlags       []string      `json:"low_profile_flags,omitempty"`
+	ScalingFactor         float64       `json:"scaling_factor"`
+	Flags                 []string      `json:"flags"`
+	Regions               []Region      `json:"regions"`
+}
+
+// NewReport creates a report of the output of a calculator. Flags contains only the flags that were calculated,
+// including those of the low profile.
+func NewReport(c Calculator, o Output) Report {
+	return Report{
+		TotalMemory:           c.TotalMemory.Value,
+		HeadRoom:              c.HeadRoom,
+		LoadedClassCount:      c.LoadedClassCount,
+		ClassMetadata:         o.ClassMetadata,
+		ThreadCount:           o.ThreadCount.Value,
+		ThreadCountProvenance: o.ThreadCount.Provenance,
+		LowProfile:            c.LowProfile,
+		LowProfileFlags:       o.LowProfileFlags,
+		ScalingFactor:         o.Memory.ScalingFactor,
+		Flags:                 append(o.Memory.CalculatedFlags(), o.LowProfileFlags...),
+		Regions:               o.Memory.Regions(o.ThreadCount.Value),
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+func testReport(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		c calc.Calculator
+	)
+
+	it.Before(func() {
+		c = calc.Calculator{
+			GC:               calc.GCEpsilon,
+			HeadRoom:         calc.PercentageAmount(10),
+			LoadedClassCount: 100,
+			ThreadCount:      calc.ThreadCount{Value: 2, Provenance: calc.UserConfigured},
+			TotalMemory:      calc.Size{Value: calc.Gibi},
+		}
+	})
+
+	it("reports the calculation", func() {
+		o, err := c.Calculate("-Xss512k")
+		Expect(err).NotTo(HaveOccurred())
+
+		r := calc.NewReport(c, o)
+		Expect(r.TotalMemory).To(Equal(calc.Gibi))
+		Expect(r.HeadRoom).To(Equal(calc.PercentageAmount(10)))
+		Expect(r.LoadedClassCount).To(Equal(100))
+		Expect(r.ThreadCount).To(Equal(2))
+		Expect(r.ThreadCountProvenance).To(Equal(calc.UserConfigured))
+		Expect(r.ScalingFactor).To(Equal(1.0))
+		Expect(r.Flags).To(Equal(o.Memory.CalculatedFlags()))
+		Expect(r.Flags).NotTo(ContainElement("-Xss512K"))
+		Expect(r.Regions).To(Equal(o.Memory.Regions(2)))
+	})
+
+	it("marshals as JSON", func() {
+		o, err := c.Calculate("")
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := json.Marshal(calc.NewReport(c, o))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring(`"thread_count_provenance":"UserConfigured"`))
+		Expect(string(b)).To(ContainSubstring(`{"name":"stack","flag":"-Xss1M","size":2097152,"provenance":"Default"}`))
+	})
+
+	it("reports the low profile", func() {
+		c.LowProfile = true
+		c.TotalMemory = calc.Size{Value: 512 * calc.Mebi}
+
+		o, err := c.Calculate("")
+		Expect(err).NotTo(HaveOccurred())
+
+		r := calc.NewReport(c, o)
+		Expect(r.LowProfile).To(BeTrue())
+		Expect(r.ScalingFactor).To(Equal(0.5))
+		Expect(r.LowProfileFlags).To(Equal([]string{"-XX:CICompilerCount=2", "-Xshare:auto"}))
+		Expect(r.Flags).To(Equal(append(o.Memory.CalculatedFlags(), "-XX:CICompilerCount=2", "-Xshare:auto")))
+	})
+}
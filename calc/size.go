@@ -48,6 +48,39 @@ const (
 
 var SizeRE = regexp.MustCompile(fmt.Sprintf("^%s$", SizePattern))
 
+func (p Provenance) String() string {
+	switch p {
+	case Default:
+		return "Default"
+	case UserConfigured:
+		return "UserConfigured"
+	case Calculated:
+		return "Calculated"
+	default:
+		return "Unknown"
+	}
+}
+
+func (p Provenance) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Provenance) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "Default":
+		*p = Default
+	case "UserConfigured":
+		*p = UserConfigured
+	case "Calculated":
+		*p = Calculated
+	case "Unknown":
+		*p = Unknown
+	default:
+		return fmt.Errorf("unrecognized provenance %q", text)
+	}
+	return nil
+}
+
 type Size struct {
 	Value      int64
 	Provenance Provenance
@@ -158,4 +158,25 @@ func testSize(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	context("provenance", func() {
+		it("formats provenance", func() {
+			Expect(calc.Default.String()).To(Equal("Default"))
+			Expect(calc.UserConfigured.String()).To(Equal("UserConfigured"))
+			Expect(calc.Calculated.String()).To(Equal("Calculated"))
+			Expect(calc.Unknown.String()).To(Equal("Unknown"))
+		})
+
+		it("marshals provenance as text", func() {
+			Expect(calc.UserConfigured.MarshalText()).To(Equal([]byte("UserConfigured")))
+		})
+
+		it("unmarshals provenance from text", func() {
+			var p calc.Provenance
+			Expect(p.UnmarshalText([]byte("Calculated"))).To(Succeed())
+			Expect(p).To(Equal(calc.Calculated))
+
+			Expect(p.UnmarshalText([]byte("X"))).To(MatchError(`unrecognized provenance "X"`))
+		})
+	})
 }
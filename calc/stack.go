@@ -24,7 +24,7 @@ import (
 
 var (
 	DefaultStack = Stack{Value: 1 * Mebi, Provenance: Default}
-	StackRE      = regexp.MustCompile(fmt.Sprintf("^-Xss(%s)$", SizePattern))
+	StackRE      = regexp.MustCompile(fmt.Sprintf("^(?:-Xss(%s)|-XX:ThreadStackSize=(%s))$", SizePattern, SizePattern))
 )
 
 type Stack Size
@@ -43,10 +43,19 @@ func ParseStack(s string) (Stack, error) {
 		return Stack{}, fmt.Errorf("%s does not match stack pattern %s", s, StackRE.String())
 	}
 
-	z, err := ParseSize(g[1])
+	if g[1] != "" {
+		z, err := ParseSize(g[1])
+		if err != nil {
+			return Stack{}, fmt.Errorf("unable to parse stack size\n%w", err)
+		}
+		return Stack(z), nil
+	}
+
+	// -XX:ThreadStackSize is in kibibytes, and is the fourth group as SizePattern has groups of its own
+	z, err := ParseSize(g[4])
 	if err != nil {
 		return Stack{}, fmt.Errorf("unable to parse stack size\n%w", err)
 	}
 
-	return Stack(z), nil
+	return Stack{Value: z.Value * Kibi}, nil
 }
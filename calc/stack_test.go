@@ -45,4 +45,10 @@ func testStack(t *testing.T, context spec.G, it spec.S) {
 	it("parses", func() {
 		Expect(calc.ParseStack("-Xss1K")).To(Equal(calc.Stack{Value: calc.Kibi}))
 	})
+
+	it("parses -XX:ThreadStackSize in kibibytes", func() {
+		Expect(calc.MatchStack("-XX:ThreadStackSize=512")).To(BeTrue())
+		Expect(calc.ParseStack("-XX:ThreadStackSize=512")).To(Equal(calc.Stack{Value: 512 * calc.Kibi}))
+		Expect(calc.ParseStack("-XX:ThreadStackSize=1k")).To(Equal(calc.Stack{Value: calc.Mebi}))
+	})
 }
@@ -18,31 +18,44 @@ package jvmvendors
 
 import (
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
 	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
-const DefaultCertFile = "/etc/ssl/certs/ca-certificates.crt"
+const (
+	DefaultCertFile     = "/etc/ssl/certs/ca-certificates.crt"
+	DefaultCertPassword = "changeit"
+)
 
 var NormalizedDateTime = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
 
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
 type CertificateLoader struct {
-	CertFile string
-	CertDirs []string
-	Logger   log.Logger
+	CertFile        string
+	CertDirs        []string
+	IncludeAllFiles bool
+	Password        string
+	Logger          log.Logger
 }
 
 func NewCertificateLoader(logger log.Logger) CertificateLoader {
-	c := CertificateLoader{CertFile: DefaultCertFile}
+	c := CertificateLoader{CertFile: DefaultCertFile, Password: DefaultCertPassword}
 
 	if s, ok := os.LookupEnv("SSL_CERT_FILE"); ok {
 		c.CertFile = s
@@ -58,7 +71,7 @@ func NewCertificateLoader(logger log.Logger) CertificateLoader {
 }
 
 func (c *CertificateLoader) Load(path string, password string) error {
-	ks, err := DetectKeystore(path)
+	ks, err := DetectKeystore(path, password)
 	if err != nil {
 		return err
 	}
@@ -76,7 +89,10 @@ func (c *CertificateLoader) Load(path string, password string) error {
 		}
 
 		for i, b := range blocks {
-			if err := ks.Add(fmt.Sprintf("%s-%d", f, i), b); err != nil {
+			if err := ks.Add(fmt.Sprintf("%s-%d", f, i), b); errors.Is(err, ErrReadOnlyKeystore) {
+				c.Logger.Bodyf("WARNING: Unable to add container CA certificates to JVM truststore %s: %s", path, err)
+				return nil
+			} else if err != nil {
 				return fmt.Errorf("unable to add certificate %s\n%w", f, err)
 			}
 			added++
@@ -103,25 +119,71 @@ func (c CertificateLoader) certFiles() ([]string, error) {
 
 	re := regexp.MustCompile(`^[[:xdigit:]]{8}\.[\d]$`)
 	for _, d := range c.CertDirs {
-		c, err := os.ReadDir(d)
+		entries, err := os.ReadDir(d)
 		if os.IsNotExist(err) {
 			continue
 		} else if err != nil {
 			return nil, fmt.Errorf("unable to list children of %s\n%w", d, err)
 		}
 
-		for _, c := range c {
-			if c.IsDir() || !re.MatchString(c.Name()) {
+		for _, e := range entries {
+			// hidden entries include the ..data links of Kubernetes volume mounts
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+
+			if !c.IncludeAllFiles && !re.MatchString(e.Name()) {
 				continue
 			}
 
-			files = append(files, filepath.Join(d, c.Name()))
+			files = append(files, filepath.Join(d, e.Name()))
 		}
 	}
 
 	return files, nil
 }
 
+// Fingerprint returns a digest of the set of certificates that Load would add. It does not depend on the names or
+// order of the certificate files, so it can be used to detect whether the set has changed since build.
+func (c CertificateLoader) Fingerprint() (string, error) {
+	sums, err := c.CertificateFingerprints()
+	if err != nil {
+		return "", err
+	}
+
+	out := sha256.New()
+	for _, s := range sums {
+		_, _ = io.WriteString(out, s)
+	}
+
+	return hex.EncodeToString(out.Sum(nil)), nil
+}
+
+// CertificateFingerprints returns the sorted, de-duplicated hex SHA-256 fingerprints of the container CA
+// certificates.
+func (c CertificateLoader) CertificateFingerprints() ([]string, error) {
+	files, err := c.certFiles()
+	if err != nil {
+		return nil, fmt.Errorf("unable to identify cert files in %s and %s\n%w", c.CertFile, c.CertDirs, err)
+	}
+
+	var sums []string
+	for _, f := range files {
+		blocks, err := c.readBlocks(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read certificates from %s\n%w", f, err)
+		}
+
+		for _, b := range blocks {
+			sum := sha256.Sum256(b.Bytes)
+			sums = append(sums, hex.EncodeToString(sum[:]))
+		}
+	}
+
+	slices.Sort(sums)
+	return slices.Compact(sums), nil
+}
+
 func (c *CertificateLoader) Metadata() (map[string]any, error) {
 	var (
 		err      error
@@ -144,6 +206,10 @@ func (c *CertificateLoader) Metadata() (map[string]any, error) {
 		return nil, fmt.Errorf("unable to create file listing for %s\n%w", c.CertDirs, err)
 	}
 
+	if c.IncludeAllFiles {
+		metadata["cert-dir-all-files"] = true
+	}
+
 	return metadata, nil
 }
 
@@ -153,17 +219,106 @@ func (c CertificateLoader) readBlocks(path string) ([]*pem.Block, error) {
 		blocks []*pem.Block
 	)
 
-	rest, err := os.ReadFile(path)
+	in, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read %s\n%w", path, err)
 	}
 
-	for {
+	found := false
+	for rest := in; ; {
 		block, rest = pem.Decode(rest)
 		if block == nil {
 			break
 		}
-		blocks = append(blocks, block)
+		found = true
+
+		switch block.Type {
+		case "CERTIFICATE":
+			blocks = append(blocks, block)
+		case "PKCS7":
+			certs, err := pkcs7Certificates(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode PKCS7 block in %s\n%w", path, err)
+			}
+			blocks = append(blocks, certs...)
+		default:
+			c.Logger.Debugf("Ignoring %s block in %s", block.Type, path)
+		}
+	}
+
+	if found || len(in) == 0 {
+		return blocks, nil
+	}
+
+	return c.readDERBlocks(in), nil
+}
+
+// readDERBlocks sniffs binary certificate bundles, trying a single DER certificate, a PKCS7 bundle and a PKCS12
+// truststore in turn. Content in any other format is ignored.
+func (c CertificateLoader) readDERBlocks(in []byte) []*pem.Block {
+	if cert, err := x509.ParseCertificate(in); err == nil {
+		return []*pem.Block{{Type: "CERTIFICATE", Bytes: cert.Raw}}
+	}
+
+	if blocks, err := pkcs7Certificates(in); err == nil {
+		return blocks
+	}
+
+	for _, password := range []string{"", c.Password} {
+		if certs, err := pkcs12.DecodeTrustStore(in, password); err == nil {
+			var blocks []*pem.Block
+			for _, cert := range certs {
+				blocks = append(blocks, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+			}
+			return blocks
+		}
+	}
+
+	return nil
+}
+
+// pkcs7Certificates extracts the certificates from a degenerate PKCS7 SignedData structure, as used by .p7b files.
+func pkcs7Certificates(in []byte) ([]*pem.Block, error) {
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}
+	if err := unmarshalDER(in, &contentInfo); err != nil {
+		return nil, err
+	}
+	if !contentInfo.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unsupported PKCS7 content type %s", contentInfo.ContentType)
+	}
+
+	var signedData asn1.RawValue
+	if err := unmarshalDER(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+
+	var blocks []*pem.Block
+	for rest := signedData.Bytes; len(rest) > 0; {
+		var field asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &field); err != nil {
+			return nil, err
+		}
+
+		// certificates are the only [0] IMPLICIT field of SignedData
+		if field.Class != asn1.ClassContextSpecific || field.Tag != 0 {
+			continue
+		}
+
+		for certs := field.Bytes; len(certs) > 0; {
+			var cert asn1.RawValue
+			if certs, err = asn1.Unmarshal(certs, &cert); err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, &pem.Block{Type: "CERTIFICATE", Bytes: cert.FullBytes})
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, errors.New("no certificates found in PKCS7 structure")
 	}
 
 	return blocks, nil
@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -45,6 +46,7 @@ func testCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(c.CertFile).To(Equal(jvmvendors.DefaultCertFile))
 			Expect(c.CertDirs).To(BeNil())
+			Expect(c.Password).To(Equal(jvmvendors.DefaultCertPassword))
 		})
 
 		context("$SSL_CERT_DIR", func() {
@@ -75,6 +77,47 @@ func testCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("fingerprint", func() {
+		it("does not depend on file names or duplicates", func() {
+			a := jvmvendors.CertificateLoader{
+				CertFile: filepath.Join("testdata", "certificates", "certificate-1.pem"),
+				CertDirs: []string{filepath.Join("testdata", "certificates")},
+			}
+			b := jvmvendors.CertificateLoader{
+				CertDirs: []string{filepath.Join("testdata", "certificates")},
+			}
+
+			expected, err := b.Fingerprint()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Fingerprint()).To(Equal(expected))
+		})
+
+		it("changes when the certificates change", func() {
+			a := jvmvendors.CertificateLoader{
+				CertFile: filepath.Join("testdata", "certificates", "certificate-1.pem"),
+			}
+			b := jvmvendors.CertificateLoader{
+				CertDirs: []string{filepath.Join("testdata", "certificates")},
+			}
+
+			expected, err := b.Fingerprint()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Fingerprint()).NotTo(Equal(expected))
+		})
+
+		it("returns sorted fingerprints of each certificate", func() {
+			c := jvmvendors.CertificateLoader{
+				CertFile: filepath.Join("testdata", "certificates", "certificate-1.pem"),
+				CertDirs: []string{filepath.Join("testdata", "certificates")},
+			}
+
+			sums, err := c.CertificateFingerprints()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(HaveLen(2))
+			Expect(sums).To(BeEquivalentTo(slices.Sorted(slices.Values(sums))))
+		})
+	})
+
 	context("load pkcs12", func() {
 		var (
 			path string
@@ -149,6 +192,74 @@ func testCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 			Expect(ks).To(HaveLen(3))
 		})
 
+		it("loads additional certificates from DER, PKCS7 and PKCS12 files", func() {
+			for _, f := range []string{"certificate.der", "certificate.p7b", "certificate-pem.p7b", "certificate.p12"} {
+				c := jvmvendors.CertificateLoader{
+					CertFile: filepath.Join("testdata", "certificate-bundles", f),
+					Logger:   log.NewDiscardLogger(),
+				}
+
+				Expect(c.Load(path, "changeit")).To(Succeed())
+			}
+
+			in, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			ks, err := pkcs12.DecodeTrustStore(in, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(HaveLen(5))
+		})
+
+		it("ignores files without hashed names in directories", func() {
+			c := jvmvendors.CertificateLoader{
+				CertDirs: []string{filepath.Join("testdata", "certificate-bundles")},
+				Logger:   log.NewDiscardLogger(),
+			}
+
+			Expect(c.Load(path, "changeit")).To(Succeed())
+
+			in, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			ks, err := pkcs12.DecodeTrustStore(in, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(HaveLen(1))
+		})
+
+		it("loads all files in directories", func() {
+			c := jvmvendors.CertificateLoader{
+				CertDirs:        []string{filepath.Join("testdata", "certificate-bundles")},
+				IncludeAllFiles: true,
+				Logger:          log.NewDiscardLogger(),
+			}
+
+			Expect(c.Load(path, "changeit")).To(Succeed())
+
+			in, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			ks, err := pkcs12.DecodeTrustStore(in, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(HaveLen(6))
+		})
+
+		it("ignores keys and requests in directories with certificates", func() {
+			c := jvmvendors.CertificateLoader{
+				CertDirs:        []string{filepath.Join("testdata", "certificate-mixed")},
+				IncludeAllFiles: true,
+				Logger:          log.NewDiscardLogger(),
+			}
+
+			Expect(c.Load(path, "changeit")).To(Succeed())
+
+			in, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			ks, err := pkcs12.DecodeTrustStore(in, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(HaveLen(3))
+		})
+
 		internal.SkipIfRoot(it, "does not return error when keystore is read-only", func() {
 			Expect(os.Chmod(path, 0555)).To(Succeed())
 
@@ -246,6 +357,28 @@ func testCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 			Expect(ks.Aliases()).To(HaveLen(3))
 		})
 
+		it("ignores keys and requests in directories with certificates", func() {
+			c := jvmvendors.CertificateLoader{
+				CertDirs:        []string{filepath.Join("testdata", "certificate-mixed")},
+				IncludeAllFiles: true,
+				Logger:          log.NewDiscardLogger(),
+			}
+
+			Expect(c.Load(path, "changeit")).To(Succeed())
+
+			in, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = in.Close() }()
+
+			ks := keystore.New()
+			err = ks.Load(in, []byte("changeit"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks.Aliases()).To(HaveLen(3))
+			for _, a := range ks.Aliases() {
+				Expect(ks.IsTrustedCertificateEntry(a)).To(BeTrue())
+			}
+		})
+
 		internal.SkipIfRoot(it, "does not return error when keystore is read-only", func() {
 			Expect(os.Chmod(path, 0555)).To(Succeed())
 
@@ -266,4 +399,21 @@ func testCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 			Expect(ks.Aliases()).To(HaveLen(1))
 		})
 	})
+
+	context("load bcfks", func() {
+		it("does not add certificates to a read-only keystore format", func() {
+			path := filepath.Join(t.TempDir(), "test-keystore.bcfks")
+			before, err := os.ReadFile(filepath.Join("testdata", "test-keystore.bcfks"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(path, before, 0644)).To(Succeed())
+
+			c := jvmvendors.CertificateLoader{
+				CertDirs: []string{filepath.Join("testdata", "certificates")},
+				Logger:   log.NewDiscardLogger(),
+			}
+
+			Expect(c.Load(path, "changeit")).To(Succeed())
+			Expect(os.ReadFile(path)).To(Equal(before))
+		})
+	})
 }
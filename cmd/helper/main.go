@@ -19,7 +19,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/buildpacks/libcnb/v2"
 	"github.com/miekg/dns"
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/paketo-buildpacks/libpak/v2/sherpa"
@@ -38,27 +40,42 @@ func main() {
 
 			cl = jvmvendors.NewCertificateLoader(l)
 
-			a  = helper.ActiveProcessorCount{Logger: l}
+			a = helper.ActiveProcessorCount{
+				CgroupPath: helper.DefaultCgroupPath,
+				CgroupRoot: helper.DefaultCgroupRoot,
+				Logger:     l,
+			}
 			c  = helper.SecurityProvidersConfigurer{Logger: l}
 			d  = helper.LinkLocalDNS{Logger: l}
 			j  = helper.JavaOpts{Logger: l}
+			ja = helper.JavaAgents{Logger: l}
 			jh = helper.JVMHeapDump{Logger: l}
 			m  = helper.MemoryCalculator{
+				CgroupPath:        helper.DefaultCgroupPath,
+				CgroupRoot:        helper.DefaultCgroupRoot,
 				Logger:            l,
 				MemoryLimitPathV1: helper.DefaultMemoryLimitPathV1,
-				MemoryLimitPathV2: helper.DefaultMemoryLimitPathV2,
 				MemoryInfoPath:    helper.DefaultMemoryInfoPath,
+				ReportPath:        helper.DefaultReportPath,
 			}
+			jg = helper.JVMGC{Logger: l, ActiveProcessorCount: a, MemoryCalculator: m}
 			o  = helper.OpenSSLCertificateLoader{CertificateLoader: cl, Logger: l}
 			s8 = helper.SecurityProvidersClasspath8{Logger: l}
 			s9 = helper.SecurityProvidersClasspath9{Logger: l}
 			d8 = helper.Debug8{Logger: l}
 			d9 = helper.Debug9{Logger: l}
+			g8 = helper.GCLog8{Logger: l}
+			g9 = helper.GCLog9{Logger: l}
 			jm = helper.JMX{Logger: l}
 			n  = helper.NMT{Logger: l}
 			jf = helper.JFR{Logger: l}
+			tk = helper.TLSClientKeystore{CertificateLoader: cl, Logger: l}
 		)
 
+		if len(os.Args) > 1 && os.Args[1] == "truststore" {
+			return helper.TruststoreInspector{CertificateLoader: cl, Out: os.Stdout}.Run(os.Args[2:])
+		}
+
 		file := "/etc/resolv.conf"
 		d.Config, err = dns.ClientConfigFromFile(file)
 		if err != nil {
@@ -67,7 +84,9 @@ func main() {
 
 		return sherpa.Helpers(map[string]sherpa.ExecD{
 			"active-processor-count":         a,
+			"java-agents":                    withBindings(func(b libcnb.Bindings) sherpa.ExecD { ja.Bindings = b; return ja }),
 			"java-opts":                      j,
+			"jvm-gc":                         jg,
 			"jvm-heap":                       jh,
 			"link-local-dns":                 d,
 			"memory-calculator":              m,
@@ -77,9 +96,24 @@ func main() {
 			"security-providers-configurer":  c,
 			"debug-8":                        d8,
 			"debug-9":                        d9,
-			"jmx":                            jm,
+			"gc-log-8":                       g8,
+			"gc-log-9":                       g9,
+			"jmx":                            withBindings(func(b libcnb.Bindings) sherpa.ExecD { jm.Bindings = b; return jm }),
 			"nmt":                            n,
-			"jfr":                            jf,
+			"jfr":                            withBindings(func(b libcnb.Bindings) sherpa.ExecD { jf.Bindings = b; return jf }),
+			"tls-client-keystore":            withBindings(func(b libcnb.Bindings) sherpa.ExecD { tk.Bindings = b; return tk }),
 		})
 	})
 }
+
+// withBindings reads the bindings only when a helper that uses them runs, so that a binding that cannot be read does
+// not fail the other helpers.
+type withBindings func(libcnb.Bindings) sherpa.ExecD
+
+func (w withBindings) Execute() (map[string]string, error) {
+	b, err := libcnb.NewBindings(filepath.Dir(libcnb.DefaultPlatformBindingsLocation))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bindings\n%w", err)
+	}
+	return w(b).Execute()
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+	"github.com/paketo-buildpacks/jvm-vendors/count"
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+// main runs the memory calculator offline, so that containers can be sized with the same calculation that is used
+// at launch.
+func main() {
+	sherpa.Execute(func() error {
+		var (
+			c = calc.Calculator{ThreadCount: calc.DefaultThreadCountValue}
+
+			format      = flag.String("format", "text", "output format, text or json")
+			gc          = flag.String("gc", "", "garbage collector, one of Epsilon, G1, Parallel, Serial, Shenandoah or Z (default selected by the JVM)")
+			jvmPath     = flag.String("jvm-path", "", "path to the JVM whose classes are counted")
+			appPath     = flag.String("app-path", "", "path to the application whose classes are counted")
+			codeCache   = flag.String("code-cache", "", "reserved code cache, a size, a percentage of total memory or max() or min() of them")
+			directMem   = flag.String("direct-memory", "", "direct memory, a size, a percentage of total memory or max() or min() of them (default increased if -app-path uses Netty)")
+			headRoom    = flag.String("head-room", strconv.Itoa(helper.DefaultHeadroom), "memory that is not allocated to the JVM, a percentage of total memory, a size or max() or min() of them")
+			minimum     = flag.Bool("minimum", false, "search for the smallest total memory, up to -total-memory, that the calculation succeeds for")
+			opts        = flag.String("java-tool-options", os.Getenv("JAVA_TOOL_OPTIONS"), "user configured JVM flags (default $JAVA_TOOL_OPTIONS)")
+			processors  = flag.Int("processors", 1, "processors available to the container, for the thread count inferred from -app-path")
+			threadCount = flag.Int("thread-count", calc.DefaultThreadCount, "number of threads, inferred from the configuration in -app-path if not set")
+			totalMemory = flag.String("total-memory", "", "total memory available to the JVM, such as 1G")
+		)
+
+		flag.IntVar(&c.JavaVersion, "java-version", 0, "major version of the JVM (default assumes the oldest)")
+		flag.IntVar(&c.LoadedClassCount, "loaded-class-count", 0, "number of loaded classes (default counted from -app-path and -jvm-path)")
+		flag.BoolVar(&c.LowProfile, "low-profile", true, "apply the low memory profile below "+calc.Size{Value: calc.LowProfileThreshold}.String())
+		flag.IntVar(&c.MallocArenas, "malloc-arenas", calc.DefaultMallocArenas, "value of $MALLOC_ARENA_MAX, contributed by the JRE layer")
+		flag.StringVar(&c.NativeMemoryTracking, "nmt", calc.NMTSummary, "Native Memory Tracking level, one of off, summary or detail")
+		flag.Parse()
+
+		if *format != "text" && *format != "json" {
+			return fmt.Errorf("unsupported format %s, expected text or json", *format)
+		}
+		c.GC = *gc
+
+		set := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		if set["head-room"] {
+			a, err := calc.ParseHeadRoom(*headRoom)
+			if err != nil {
+				return fmt.Errorf("unable to parse -head-room\n%w", err)
+			}
+			c.HeadRoom = a
+		}
+
+		if *codeCache != "" {
+			a, err := calc.ParseAmount(*codeCache)
+			if err != nil {
+				return fmt.Errorf("unable to parse -code-cache\n%w", err)
+			}
+			c.ReservedCodeCache = &a
+		}
+
+		if *directMem != "" {
+			a, err := calc.ParseAmount(*directMem)
+			if err != nil {
+				return fmt.Errorf("unable to parse -direct-memory\n%w", err)
+			}
+			c.DirectMemory = &a
+		} else if *appPath != "" {
+			libraries, err := helper.FindDirectMemoryLibraries(*appPath)
+			if err != nil {
+				return fmt.Errorf("unable to find libraries that use direct memory\n%w", err)
+			}
+			if len(libraries) > 0 {
+				c.DirectMemory = &helper.DirectMemoryLibrariesAmount
+			}
+		}
+
+		if set["thread-count"] {
+			c.ThreadCount = calc.ThreadCount{Value: *threadCount, Provenance: calc.UserConfigured}
+		} else if *appPath != "" {
+			if *processors < 1 {
+				return fmt.Errorf("-processors must be at least 1")
+			}
+
+			t, ok, err := helper.InferThreadCount(*appPath, c.JavaVersion, *processors)
+			if err != nil {
+				return fmt.Errorf("unable to infer thread count\n%w", err)
+			}
+			if ok {
+				c.ThreadCount = t.ThreadCount
+			}
+		}
+
+		if !set["loaded-class-count"] {
+			if *appPath == "" || *jvmPath == "" {
+				return fmt.Errorf("-loaded-class-count or both -app-path and -jvm-path must be set")
+			}
+
+			jvmClassCount, err := count.Classes(*jvmPath)
+			if err != nil {
+				return fmt.Errorf("unable to count classes in %s\n%w", *jvmPath, err)
+			}
+
+			appClassCount, err := count.Classes(*appPath)
+			if err != nil {
+				return fmt.Errorf("unable to count classes in %s\n%w", *appPath, err)
+			}
+
+			c.LoadedClassCount = int(float64(jvmClassCount+appClassCount) * helper.ClassLoadFactor)
+		}
+
+		switch {
+		case *totalMemory != "":
+			s, err := calc.ParseSize(*totalMemory)
+			if err != nil {
+				return fmt.Errorf("unable to parse -total-memory\n%w", err)
+			}
+			c.TotalMemory = s
+		case *minimum:
+			c.TotalMemory = calc.Size{Value: helper.MaxJVMSize}
+		default:
+			return fmt.Errorf("-total-memory must be set")
+		}
+
+		if *minimum {
+			s, err := c.MinimumTotalMemory(*opts, c.TotalMemory)
+			if err != nil {
+				return fmt.Errorf("unable to find minimum total memory\n%w", err)
+			}
+			c.TotalMemory = s
+		}
+
+		// as at launch, the low profile only applies to small containers
+		c.LowProfile = c.LowProfile && c.TotalMemory.Value < calc.LowProfileThreshold
+
+		o, err := c.Calculate(*opts)
+		if err != nil {
+			return fmt.Errorf("unable to calculate memory configuration\n%w", err)
+		}
+		r := calc.NewReport(c, o)
+
+		if *format == "json" {
+			e := json.NewEncoder(os.Stdout)
+			e.SetIndent("", "  ")
+			if err := e.Encode(r); err != nil {
+				return fmt.Errorf("unable to encode JSON\n%w", err)
+			}
+			return nil
+		}
+
+		return writeText(r, *opts)
+	})
+}
+
+func writeText(r calc.Report, opts string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(w, "Total Memory:\t%s\n", calc.Size{Value: r.TotalMemory})
+	_, _ = fmt.Fprintf(w, "Thread Count:\t%d\n", r.ThreadCount)
+	_, _ = fmt.Fprintf(w, "Loaded Class Count:\t%d\n", r.LoadedClassCount)
+	_, _ = fmt.Fprintf(w, "Headroom:\t%s\n", r.HeadRoom)
+	_, _ = fmt.Fprintf(w, "JAVA_TOOL_OPTIONS:\t%s\n\n", strings.TrimSpace(opts+" "+strings.Join(r.Flags, " ")))
+
+	_, _ = fmt.Fprintf(w, "REGION\tSIZE\tPROVENANCE\tFLAG\n")
+	for _, g := range r.Regions {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", g.Name, calc.Size{Value: g.Size}, g.Provenance, g.Flag)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("unable to write output\n%w", err)
+	}
+	return nil
+}
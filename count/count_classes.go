@@ -25,10 +25,20 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-var ClassExtensions = []string{".class", ".classdata", ".clj", ".groovy", ".kts"}
+var (
+	ArchiveExtensions = []string{".ear", ".jar", ".war"}
+	ClassExtensions   = []string{".class", ".classdata", ".clj", ".groovy", ".kts"}
+
+	// MaxBufferedArchiveSize is the largest uncompressed size of a compressed nested archive that is buffered in memory.
+	// Each of the archives counted in parallel holds at most one such buffer per level of nesting at a time.
+	MaxBufferedArchiveSize = int64(8 * 1024 * 1024)
+)
 
 func Classes(path string) (int, error) {
 	file := filepath.Join(path, "lib", "modules")
@@ -41,22 +51,61 @@ func Classes(path string) (int, error) {
 	}
 }
 
+// JarClasses counts the class files in path and in the archives below it. Archives are read through their central
+// directory and counted in parallel. Jars nested in jars and wars, such as those in BOOT-INF/lib and WEB-INF/lib, and
+// the wars and jars of an ear are counted too.
 func JarClasses(path string) (int, error) {
-	count := 0
+	var (
+		count    atomic.Int64
+		archives = make(chan string)
+		wg       sync.WaitGroup
 
-	if err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	failed := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr
+	}
+
+	for range runtime.GOMAXPROCS(0) {
+		wg.Go(func() {
+			for a := range archives {
+				if failed() != nil {
+					continue
+				}
+
+				c, err := fileClasses(a)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+				count.Add(int64(c))
+			}
+		})
+	}
+
+	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		for _, e := range ClassExtensions {
-			if strings.HasSuffix(path, e) {
-				count++
-				return nil
-			}
+		if err := failed(); err != nil {
+			return err
+		}
+
+		if isClass(path) {
+			count.Add(1)
+			return nil
 		}
 
-		if !strings.HasSuffix(path, ".jar") || info.IsDir() {
+		if !isArchive(path, ArchiveExtensions) || info.IsDir() {
 			return nil
 		}
 
@@ -67,33 +116,21 @@ func JarClasses(path string) (int, error) {
 			return nil
 		}
 
-		z, err := zip.OpenReader(path)
-		if err != nil {
-			if !(errors.Is(err, zip.ErrFormat)) {
-				return fmt.Errorf("unable to open Jar %s\n%w", path, err)
-			} else {
-				return nil
-			}
-		}
-		defer func() { _ = z.Close() }()
+		archives <- path
+		return nil
+	})
 
-		for _, f := range z.File {
-			if strings.HasSuffix(f.FileInfo().Name(), ".jar") {
-				c, err := nestedJarContents(f)
-				if err != nil {
-					return fmt.Errorf("unable to counted nested jar\n%w", err)
-				}
-				count += c
-			}
-			count += jarContents(f)
-		}
+	close(archives)
+	wg.Wait()
 
-		return nil
-	}); err != nil {
+	if err == nil {
+		err = failed()
+	}
+	if err != nil {
 		return 0, fmt.Errorf("unable to walk %s\n%w", path, err)
 	}
 
-	return count, nil
+	return int(count.Load()), nil
 }
 
 func ModuleClasses(file string) (int, error) {
@@ -141,45 +178,126 @@ func JarClassesFrom(paths ...string) (int, int, error) {
 	return agentClassCount, skippedPaths, nil
 }
 
-func jarContents(file *zip.File) int {
-	var count = 0
-	for _, e := range ClassExtensions {
-		if strings.HasSuffix(file.Name, e) {
-			count++
-			break
+func isArchive(name string, extensions []string) bool {
+	for _, e := range extensions {
+		if strings.HasSuffix(name, e) {
+			return true
 		}
 	}
-	return count
+	return false
 }
 
-func nestedJarContents(jarFile *zip.File) (int, error) {
-	var count = 0
+func isClass(name string) bool {
+	return isArchive(name, ClassExtensions)
+}
 
-	reader, err := jarFile.Open()
+// nestedArchiveExtensions returns the extensions of the archives nested in an archive that are counted. An ear's
+// modules and the libraries of its wars are counted, but the jars nested in a nested jar are not.
+func nestedArchiveExtensions(name string, nested bool) []string {
+	switch {
+	case strings.HasSuffix(name, ".ear"):
+		return []string{".jar", ".war"}
+	case strings.HasSuffix(name, ".war"), !nested:
+		return []string{".jar"}
+	default:
+		return nil
+	}
+}
+
+func fileClasses(path string) (int, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return 0, fmt.Errorf("unable to open nested jar\n%w", err)
+		return 0, fmt.Errorf("unable to open Jar %s\n%w", path, err)
 	}
-	defer func() { _ = reader.Close() }()
+	defer func() { _ = f.Close() }()
 
-	var b bytes.Buffer
-	// #nosec G110
-	//  Potential DoS vulnerability via decompression bomb here as the user controls the input JAR file
-	//  We should probably copy this in chunks, but the risk is low as the user will be just breaking their own build
-	size, err := io.Copy(&b, reader)
+	info, err := f.Stat()
 	if err != nil {
-		return 0, fmt.Errorf("error copying nested Jar \n%w", err)
+		return 0, fmt.Errorf("unable to stat Jar %s\n%w", path, err)
 	}
-	br := bytes.NewReader(b.Bytes())
-	nj, err := zip.NewReader(br, size)
+
+	c, err := archiveClasses(f, info.Size(), path, false)
 	if err != nil {
-		if !(errors.Is(err, zip.ErrFormat)) {
-			return 0, fmt.Errorf("error reading nested Jar contents\n%w", err)
-		} else {
+		return 0, fmt.Errorf("unable to count Jar %s\n%w", path, err)
+	}
+	return c, nil
+}
+
+// archiveClasses counts the classes in the archive r and the archives nested in it. Archives that are not valid zip
+// files are skipped.
+func archiveClasses(r io.ReaderAt, size int64, name string, nested bool) (int, error) {
+	z, err := zip.NewReader(r, size)
+	if errors.Is(err, zip.ErrFormat) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("unable to read archive\n%w", err)
+	}
+
+	extensions := nestedArchiveExtensions(name, nested)
+
+	count := 0
+	for _, f := range z.File {
+		if isClass(f.Name) {
+			count++
+		} else if isArchive(f.Name, extensions) {
+			c, err := nestedArchiveClasses(r, f)
+			if err != nil {
+				return 0, fmt.Errorf("unable to count nested archive %s\n%w", f.Name, err)
+			}
+			count += c
+		}
+	}
+
+	return count, nil
+}
+
+// nestedArchiveClasses counts the classes in an archive nested in r. Stored archives, which Spring Boot requires, are
+// read in place. Compressed archives are decompressed into memory up to MaxBufferedArchiveSize, and to a temporary
+// file beyond it.
+func nestedArchiveClasses(r io.ReaderAt, f *zip.File) (int, error) {
+	size := int64(f.UncompressedSize64)
+
+	if f.Method == zip.Store {
+		offset, err := f.DataOffset()
+		if err != nil {
+			return 0, fmt.Errorf("unable to locate archive data\n%w", err)
+		}
+		return archiveClasses(io.NewSectionReader(r, offset, size), size, f.Name, true)
+	}
+
+	in, err := f.Open()
+	if errors.Is(err, zip.ErrAlgorithm) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("unable to open archive\n%w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	// the zip reader fails if an entry decompresses to more than its declared size, which bounds the copy
+	if size <= MaxBufferedArchiveSize {
+		b := bytes.NewBuffer(make([]byte, 0, size))
+		if _, err := io.Copy(b, in); errors.Is(err, zip.ErrFormat) || errors.Is(err, zip.ErrChecksum) {
 			return 0, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("unable to decompress archive\n%w", err)
 		}
+		return archiveClasses(bytes.NewReader(b.Bytes()), int64(b.Len()), f.Name, true)
+	}
+
+	t, err := os.CreateTemp("", "nested-archive-*")
+	if err != nil {
+		return 0, fmt.Errorf("unable to create temporary file\n%w", err)
 	}
-	for _, nestedJar := range nj.File {
-		count += jarContents(nestedJar)
+	defer func() {
+		_ = t.Close()
+		_ = os.Remove(t.Name())
+	}()
+
+	n, err := io.Copy(t, in)
+	if errors.Is(err, zip.ErrFormat) || errors.Is(err, zip.ErrChecksum) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("unable to decompress archive to %s\n%w", t.Name(), err)
 	}
-	return count, nil
+	return archiveClasses(t, n, f.Name, true)
 }
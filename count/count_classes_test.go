@@ -17,6 +17,9 @@
 package count_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -79,4 +82,134 @@ func testCountClasses(t *testing.T, context spec.G, it spec.S) {
 
 		Expect(count.Classes(path)).To(Equal(0))
 	})
+
+	context("archive layouts", func() {
+		it("counts Spring Boot jars with stored libraries", func() {
+			lib := archive(zip.Store, classes("com/example/lib/", 3)...)
+			writeArchive(t, filepath.Join(path, "app.jar"), zip.Store, append(classes("BOOT-INF/classes/com/example/", 2),
+				entry{"BOOT-INF/lib/lib-1.jar", lib}, entry{"BOOT-INF/lib/lib-2.jar", lib})...)
+
+			Expect(count.Classes(path)).To(Equal(8))
+		})
+
+		it("counts wars with compressed libraries", func() {
+			lib := archive(zip.Deflate, classes("com/example/lib/", 3)...)
+			writeArchive(t, filepath.Join(path, "app.war"), zip.Deflate, append(classes("WEB-INF/classes/com/example/", 2),
+				entry{"WEB-INF/lib/lib-1.jar", lib}, entry{"WEB-INF/lib-provided/lib-2.jar", lib})...)
+
+			Expect(count.Classes(path)).To(Equal(8))
+		})
+
+		it("counts compressed libraries larger than the buffer", func() {
+			max := count.MaxBufferedArchiveSize
+			count.MaxBufferedArchiveSize = 1
+			defer func() { count.MaxBufferedArchiveSize = max }()
+
+			lib := archive(zip.Deflate, classes("com/example/lib/", 3)...)
+			writeArchive(t, filepath.Join(path, "app.war"), zip.Deflate, entry{"WEB-INF/lib/lib.jar", lib})
+
+			Expect(count.Classes(path)).To(Equal(3))
+		})
+
+		it("counts the modules of ears", func() {
+			lib := archive(zip.Deflate, classes("com/example/lib/", 3)...)
+			war := archive(zip.Deflate, append(classes("WEB-INF/classes/com/example/", 2), entry{"WEB-INF/lib/lib.jar", lib})...)
+			writeArchive(t, filepath.Join(path, "app.ear"), zip.Deflate,
+				entry{"web.war", war}, entry{"ejb.jar", lib}, entry{"lib/lib.jar", lib})
+
+			Expect(count.Classes(path)).To(Equal(11))
+		})
+
+		it("does not count jars nested in nested jars", func() {
+			inner := archive(zip.Store, classes("com/example/inner/", 3)...)
+			lib := archive(zip.Store, append(classes("com/example/lib/", 1), entry{"inner.jar", inner})...)
+			writeArchive(t, filepath.Join(path, "app.jar"), zip.Store, entry{"BOOT-INF/lib/lib.jar", lib})
+
+			Expect(count.Classes(path)).To(Equal(1))
+		})
+
+		it("skips invalid nested archives", func() {
+			writeArchive(t, filepath.Join(path, "app.jar"), zip.Deflate,
+				entry{"BOOT-INF/lib/bad.jar", []byte("not a jar")}, entry{"Alpha.class", nil})
+
+			Expect(count.Classes(path)).To(Equal(1))
+		})
+
+		it("counts many archives in parallel", func() {
+			for i := range 50 {
+				writeArchive(t, filepath.Join(path, fmt.Sprintf("lib-%d.jar", i)), zip.Deflate, classes("com/example/", 10)...)
+			}
+
+			Expect(count.Classes(path)).To(Equal(500))
+		})
+	})
+}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+func classes(prefix string, n int) []entry {
+	var e []entry
+	for i := range n {
+		e = append(e, entry{fmt.Sprintf("%sClass%d.class", prefix, i), []byte("class")})
+	}
+	return e
+}
+
+func archive(method uint16, entries ...entry) []byte {
+	b := &bytes.Buffer{}
+	w := zip.NewWriter(b)
+	for _, e := range entries {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: e.name, Method: method})
+		if err != nil {
+			panic(err)
+		}
+		if _, err := f.Write(e.data); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return b.Bytes()
+}
+
+func writeArchive(tb testing.TB, path string, method uint16, entries ...entry) {
+	if err := os.WriteFile(path, archive(method, entries...), 0600); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// BenchmarkJarClasses counts the classes of generated fat jars with 150 libraries of 200 classes each.
+func BenchmarkJarClasses(b *testing.B) {
+	for _, layout := range []struct {
+		name    string
+		file    string
+		classes string
+		lib     string
+		method  uint16
+	}{
+		{"spring-boot", "app.jar", "BOOT-INF/classes/", "BOOT-INF/lib/", zip.Store},
+		{"war", "app.war", "WEB-INF/classes/", "WEB-INF/lib/", zip.Deflate},
+	} {
+		b.Run(layout.name, func(b *testing.B) {
+			lib := archive(zip.Deflate, classes("com/example/lib/", 200)...)
+
+			entries := classes(layout.classes+"com/example/", 2_000)
+			for i := range 150 {
+				entries = append(entries, entry{fmt.Sprintf("%slib-%d.jar", layout.lib, i), lib})
+			}
+
+			path := b.TempDir()
+			writeArchive(b, filepath.Join(path, layout.file), layout.method, entries...)
+
+			for b.Loop() {
+				if c, err := count.JarClasses(path); err != nil || c != 32_000 {
+					b.Fatalf("unexpected count %d: %v", c, err)
+				}
+			}
+		})
+	}
 }
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package count
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Fingerprint returns a digest of the names, types and sizes of the files in path, which is cheap enough to compute
+// at launch to decide whether a class count made at build time is still valid. Modification times are not included,
+// as image exporters reset them.
+func Fingerprint(path string) (string, error) {
+	h := sha256.New()
+
+	if err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+
+		size := int64(0)
+		if info.Mode().IsRegular() {
+			size = info.Size()
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00%d\n", filepath.ToSlash(rel), info.Mode().Type(), size)
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("unable to walk %s\n%w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package count_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/count"
+)
+
+func testFingerprint(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path        string
+		fingerprint string
+	)
+
+	it.Before(func() {
+		path = t.TempDir()
+
+		Expect(os.WriteFile(filepath.Join(path, "alpha.class"), []byte("alpha"), 0600)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "bravo"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "bravo", "charlie.jar"), []byte("charlie"), 0600)).To(Succeed())
+
+		var err error
+		fingerprint, err = count.Fingerprint(path)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("is stable", func() {
+		Expect(count.Fingerprint(path)).To(Equal(fingerprint))
+	})
+
+	it("ignores modification times", func() {
+		Expect(os.Chtimes(filepath.Join(path, "alpha.class"), time.Unix(315532800, 0), time.Unix(315532800, 0))).To(Succeed())
+
+		Expect(count.Fingerprint(path)).To(Equal(fingerprint))
+	})
+
+	it("changes when a file changes size", func() {
+		Expect(os.WriteFile(filepath.Join(path, "bravo", "charlie.jar"), []byte("charlie-2"), 0600)).To(Succeed())
+
+		Expect(count.Fingerprint(path)).NotTo(Equal(fingerprint))
+	})
+
+	it("changes when a file is added", func() {
+		Expect(os.WriteFile(filepath.Join(path, "delta.class"), []byte{}, 0600)).To(Succeed())
+
+		Expect(count.Fingerprint(path)).NotTo(Equal(fingerprint))
+	})
+
+	it("changes when a file is renamed", func() {
+		Expect(os.Rename(filepath.Join(path, "alpha.class"), filepath.Join(path, "delta.class"))).To(Succeed())
+
+		Expect(count.Fingerprint(path)).NotTo(Equal(fingerprint))
+	})
+
+	it("returns error if path does not exist", func() {
+		_, err := count.Fingerprint(filepath.Join(path, "missing"))
+		Expect(err).To(MatchError(ContainSubstring("unable to walk")))
+	})
+}
@@ -26,5 +26,6 @@ import (
 func TestUnit(t *testing.T) {
 	suite := spec.New("jvm-vendors/count", spec.Report(report.Terminal{}))
 	suite("CountClasses", testCountClasses)
+	suite("Fingerprint", testFingerprint)
 	suite.Run(t)
 }
@@ -19,15 +19,22 @@ package helper
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/mattn/go-shellwords"
 	"github.com/paketo-buildpacks/libpak/v2/log"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
 )
 
 type ActiveProcessorCount struct {
-	Logger log.Logger
+	CgroupPath string
+	CgroupRoot string
+	Logger     log.Logger
 }
 
 func (a ActiveProcessorCount) Execute() (map[string]string, error) {
@@ -47,9 +54,164 @@ func (a ActiveProcessorCount) Execute() (map[string]string, error) {
 		}
 	}
 
-	a.Logger.Debugf("Setting Active Processor Count to %d", runtime.NumCPU())
+	// from Java 17, the JVM calculates the count from the CPU quota itself, so it is only set if configured
+	if _, ok := os.LookupEnv("BPL_JVM_ACTIVE_PROCESSOR_COUNT"); !ok {
+		if v, err := semver.NewVersion(os.Getenv("BPI_JVM_VERSION")); err == nil && !v.LessThan(jvmvendors.Java17) {
+			return nil, nil
+		}
+	}
+
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	a.Logger.Debugf("Setting Active Processor Count to %d", count)
 
-	values = append(values, fmt.Sprintf("-XX:ActiveProcessorCount=%d", runtime.NumCPU()))
+	values = append(values, fmt.Sprintf("-XX:ActiveProcessorCount=%d", count))
 
 	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
 }
+
+// Count returns $BPL_JVM_ACTIVE_PROCESSOR_COUNT if it is set. Otherwise, it returns the number of processors in the
+// cgroup's cpuset, or that the process may run on if there is none, limited by the cgroup's CPU quota rounded up.
+func (a ActiveProcessorCount) Count() (int, error) {
+	if s, ok := os.LookupEnv("BPL_JVM_ACTIVE_PROCESSOR_COUNT"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("unable to parse $BPL_JVM_ACTIVE_PROCESSOR_COUNT=%s as a positive integer", s)
+		}
+		return n, nil
+	}
+
+	if a.CgroupRoot == "" {
+		return runtime.NumCPU(), nil
+	}
+
+	root := filepath.Clean(a.CgroupRoot)
+
+	dir := root
+	if p, ok, err := readCgroupV2Path(a.CgroupPath); err != nil {
+		a.Logger.Bodyf("WARNING: Unable to read %s: %s", a.CgroupPath, err)
+	} else if ok {
+		if fi, err := os.Stat(filepath.Join(root, p)); err == nil && fi.IsDir() {
+			dir = filepath.Join(root, p)
+		}
+	}
+
+	cpus, ok := a.readCPUSet(filepath.Join(dir, "cpuset.cpus.effective"))
+	if !ok {
+		cpus, ok = a.readCPUSet(filepath.Join(root, "cpuset", "cpuset.effective_cpus"))
+	}
+	if !ok {
+		cpus, ok = a.readCPUSet(filepath.Join(root, "cpuset", "cpuset.cpus"))
+	}
+	if !ok {
+		cpus = runtime.NumCPU()
+	}
+
+	// cgroup v2 quotas of the cgroup and its ancestors
+	for {
+		if q, ok := a.readCPUMax(filepath.Join(dir, "cpu.max")); ok {
+			cpus = min(cpus, q)
+		}
+
+		if dir == root || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	// cgroup v1 quotas, with the cpu and cpuacct controllers mounted separately or together
+	for _, c := range []string{"cpu", "cpu,cpuacct"} {
+		if q, ok := a.readCFSQuota(filepath.Join(root, c)); ok {
+			cpus = min(cpus, q)
+		}
+	}
+
+	return max(cpus, 1), nil
+}
+
+// readCPUMax returns the processors a cgroup v2 cpu.max quota, such as "150000 100000", allows, rounded up.
+func (a ActiveProcessorCount) readCPUMax(path string) (int, bool) {
+	s, ok := a.readCgroupFile(path)
+	if !ok {
+		return 0, false
+	}
+
+	f := strings.Fields(s)
+	if len(f) != 2 || f[0] == "max" {
+		return 0, false
+	}
+
+	return a.quotaProcessors(path, f[0], f[1])
+}
+
+// readCFSQuota returns the processors the cgroup v1 cpu.cfs_quota_us and cpu.cfs_period_us in dir allow, rounded up.
+func (a ActiveProcessorCount) readCFSQuota(dir string) (int, bool) {
+	quota, ok := a.readCgroupFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+	if !ok || quota == "-1" {
+		return 0, false
+	}
+
+	period, ok := a.readCgroupFile(filepath.Join(dir, "cpu.cfs_period_us"))
+	if !ok {
+		return 0, false
+	}
+
+	return a.quotaProcessors(dir, quota, period)
+}
+
+func (a ActiveProcessorCount) quotaProcessors(path string, quota string, period string) (int, bool) {
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil || q <= 0 {
+		a.Logger.Bodyf("WARNING: Unable to parse CPU quota %q from %s", quota, path)
+		return 0, false
+	}
+
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		a.Logger.Bodyf("WARNING: Unable to parse CPU period %q from %s", period, path)
+		return 0, false
+	}
+
+	return int((q + p - 1) / p), true
+}
+
+// readCPUSet returns the number of processors in a cpuset list, such as "0-3,8".
+func (a ActiveProcessorCount) readCPUSet(path string) (int, bool) {
+	s, ok := a.readCgroupFile(path)
+	if !ok || s == "" {
+		return 0, false
+	}
+
+	n := 0
+	for _, r := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(r, "-")
+		if !isRange {
+			last = first
+		}
+
+		f, err1 := strconv.Atoi(first)
+		l, err2 := strconv.Atoi(last)
+		if err1 != nil || err2 != nil || l < f {
+			a.Logger.Bodyf("WARNING: Unable to parse cpuset %q from %s", s, path)
+			return 0, false
+		}
+		n += l - f + 1
+	}
+
+	return n, true
+}
+
+func (a ActiveProcessorCount) readCgroupFile(path string) (string, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.Logger.Bodyf("WARNING: Unable to read %s: %s", path, err)
+		}
+		return "", false
+	}
+
+	return strings.TrimSpace(string(b)), true
+}
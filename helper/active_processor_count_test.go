@@ -19,6 +19,8 @@ package helper_test
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -60,4 +62,123 @@ func testActiveProcessorCount(t *testing.T, context spec.G, it spec.S) {
 			Expect(helper.ActiveProcessorCount{Logger: log.NewPaketoLogger(io.Discard)}.Execute()).To(BeNil())
 		})
 	})
+
+	context("cgroups", func() {
+		var (
+			a    helper.ActiveProcessorCount
+			root string
+		)
+
+		it.Before(func() {
+			root = t.TempDir()
+			a = helper.ActiveProcessorCount{
+				CgroupPath: filepath.Join(t.TempDir(), "cgroup"),
+				CgroupRoot: root,
+				Logger:     log.NewPaketoLogger(io.Discard),
+			}
+		})
+
+		write := func(path string, content string) {
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		}
+
+		it("uses all processors without limits", func() {
+			Expect(a.Count()).To(Equal(runtime.NumCPU()))
+		})
+
+		it("rounds up a cgroup v2 quota", func() {
+			write(filepath.Join(root, "cpuset.cpus.effective"), "0-7\n")
+			write(filepath.Join(root, "cpu.max"), "150000 100000\n")
+
+			Expect(a.Count()).To(Equal(2))
+		})
+
+		it("ignores an unlimited cgroup v2 quota", func() {
+			write(filepath.Join(root, "cpuset.cpus.effective"), "0-7\n")
+			write(filepath.Join(root, "cpu.max"), "max 100000\n")
+
+			Expect(a.Count()).To(Equal(8))
+		})
+
+		it("uses the quota of the process's cgroup and its ancestors", func() {
+			write(a.CgroupPath, "0::/test-cgroup/test-child\n")
+			write(filepath.Join(root, "test-cgroup", "test-child", "cpuset.cpus.effective"), "0-3,8\n")
+			write(filepath.Join(root, "test-cgroup", "test-child", "cpu.max"), "max 100000\n")
+			write(filepath.Join(root, "test-cgroup", "cpu.max"), "300000 100000\n")
+
+			Expect(a.Count()).To(Equal(3))
+		})
+
+		it("counts the processors in a cgroup v2 cpuset", func() {
+			write(filepath.Join(root, "cpuset.cpus.effective"), "0-3,8\n")
+
+			Expect(a.Count()).To(Equal(5))
+		})
+
+		it("rounds up a cgroup v1 quota", func() {
+			write(filepath.Join(root, "cpuset", "cpuset.effective_cpus"), "0-7\n")
+			write(filepath.Join(root, "cpu,cpuacct", "cpu.cfs_quota_us"), "250000\n")
+			write(filepath.Join(root, "cpu,cpuacct", "cpu.cfs_period_us"), "100000\n")
+
+			Expect(a.Count()).To(Equal(3))
+		})
+
+		it("ignores an unlimited cgroup v1 quota", func() {
+			write(filepath.Join(root, "cpuset", "cpuset.cpus"), "0-1\n")
+			write(filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "-1\n")
+			write(filepath.Join(root, "cpu", "cpu.cfs_period_us"), "100000\n")
+
+			Expect(a.Count()).To(Equal(2))
+		})
+
+		it("uses at least one processor", func() {
+			write(filepath.Join(root, "cpuset.cpus.effective"), "0-7\n")
+			write(filepath.Join(root, "cpu.max"), "1000 100000\n")
+
+			Expect(a.Count()).To(Equal(1))
+		})
+
+		it("configures active processor count", func() {
+			write(filepath.Join(root, "cpuset.cpus.effective"), "0-7\n")
+			write(filepath.Join(root, "cpu.max"), "150000 100000\n")
+
+			Expect(a.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:ActiveProcessorCount=2"}))
+		})
+	})
+
+	context("$BPL_JVM_ACTIVE_PROCESSOR_COUNT", func() {
+		it.Before(func() {
+			t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "6")
+		})
+
+		it("configures active processor count", func() {
+			Expect(helper.ActiveProcessorCount{Logger: log.NewPaketoLogger(io.Discard)}.Execute()).
+				To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:ActiveProcessorCount=6"}))
+		})
+
+		it("configures active processor count with Java 17 and later", func() {
+			t.Setenv("BPI_JVM_VERSION", "17.0.10")
+
+			Expect(helper.ActiveProcessorCount{Logger: log.NewPaketoLogger(io.Discard)}.Execute()).
+				To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:ActiveProcessorCount=6"}))
+		})
+
+		it("returns error for invalid counts", func() {
+			t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "0")
+
+			_, err := helper.ActiveProcessorCount{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+			Expect(err).To(MatchError("unable to parse $BPL_JVM_ACTIVE_PROCESSOR_COUNT=0 as a positive integer"))
+		})
+	})
+
+	context("Java 17 and later", func() {
+		it.Before(func() {
+			t.Setenv("BPI_JVM_VERSION", "17.0.10")
+		})
+
+		it("does not configure active processor count", func() {
+			Expect(helper.ActiveProcessorCount{Logger: log.NewPaketoLogger(io.Discard)}.Execute()).To(BeNil())
+		})
+	})
 }
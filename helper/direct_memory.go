@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+var (
+	// DirectMemoryLibraries are the JARs, by Maven artifact name, of libraries that allocate their I/O buffers in direct
+	// memory. Reactor Netty, gRPC, Vert.x and the Netty based drivers and clients all depend on netty-buffer.
+	DirectMemoryLibraries = []string{
+		"grpc-netty-shaded",
+		"netty-all",
+		"netty-buffer",
+		"undertow-core",
+		"xnio-nio",
+	}
+
+	// DirectMemoryLibrariesAmount is the direct memory reserved for applications that use DirectMemoryLibraries.
+	DirectMemoryLibrariesAmount = calc.Amount{
+		Function: "max",
+		Operands: []calc.Amount{calc.PercentageAmount(10), calc.AbsoluteAmount(32 * calc.Mebi)},
+	}
+)
+
+// FindDirectMemoryLibraries returns the DirectMemoryLibraries among the JARs in the application, in the order they are
+// listed. JARs nested in other JARs are not searched.
+func FindDirectMemoryLibraries(applicationPath string) ([]string, error) {
+	var found []string
+
+	if err := filepath.WalkDir(applicationPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".jar" {
+			return nil
+		}
+
+		if n := jvmvendors.NewMavenJAR(path).Name; slices.Contains(DirectMemoryLibraries, n) && !slices.Contains(found, n) {
+			found = append(found, n)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to walk %s\n%w", applicationPath, err)
+	}
+
+	slices.SortFunc(found, func(a, b string) int {
+		return slices.Index(DirectMemoryLibraries, a) - slices.Index(DirectMemoryLibraries, b)
+	})
+	return found, nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testDirectMemory(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		applicationPath string
+
+		touch = func(path string) {
+			path = filepath.Join(applicationPath, path)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte{}, 0644)).To(Succeed())
+		}
+	)
+
+	it.Before(func() {
+		applicationPath = t.TempDir()
+	})
+
+	it("finds no libraries", func() {
+		touch("BOOT-INF/lib/spring-webmvc-6.1.0.jar")
+
+		Expect(helper.FindDirectMemoryLibraries(applicationPath)).To(BeEmpty())
+	})
+
+	it("finds libraries once each in listed order", func() {
+		touch("BOOT-INF/lib/undertow-core-2.3.10.Final.jar")
+		touch("BOOT-INF/lib/netty-buffer-4.1.100.Final.jar")
+		touch("lib/netty-buffer-4.1.99.Final.jar")
+		touch("BOOT-INF/lib/reactor-netty-core-1.1.13.jar")
+
+		Expect(helper.FindDirectMemoryLibraries(applicationPath)).To(Equal([]string{"netty-buffer", "undertow-core"}))
+	})
+
+	it("returns error if the application does not exist", func() {
+		_, err := helper.FindDirectMemoryLibraries(filepath.Join(applicationPath, "missing"))
+		Expect(err).To(MatchError(HavePrefix("unable to walk")))
+	})
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+)
+
+type GCLog8 struct {
+	Logger log.Logger
+}
+
+func (g GCLog8) Execute() (map[string]string, error) {
+	if val := sherpa.ResolveBool("BPL_JVM_GC_LOG_ENABLED"); !val {
+		return nil, nil
+	}
+
+	if ok, err := hasJVMOption("-Xloggc"); err != nil {
+		return nil, err
+	} else if ok {
+		g.Logger.Body("GC logging already configured with -Xloggc")
+		return nil, nil
+	}
+
+	path, count, size, err := gcLogConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	g.Logger.Bodyf("GC logging enabled to %s, rotating %d files of %s", path, count, size)
+
+	opts := sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ",
+		"-XX:+PrintGCDetails",
+		"-XX:+PrintGCDateStamps",
+		fmt.Sprintf("-Xloggc:%s", path),
+		"-XX:+UseGCLogFileRotation",
+		fmt.Sprintf("-XX:NumberOfGCLogFiles=%d", count),
+		fmt.Sprintf("-XX:GCLogFileSize=%s", size))
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": opts}, nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testGCLog8(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+		g      = helper.GCLog8{Logger: log.NewPaketoLogger(io.Discard)}
+	)
+
+	it("does nothing if $BPL_JVM_GC_LOG_ENABLED is not set", func() {
+		Expect(g.Execute()).To(BeNil())
+	})
+
+	context("$BPL_JVM_GC_LOG_ENABLED", func() {
+		it.Before(func() {
+			t.Setenv("BPL_JVM_GC_LOG_ENABLED", "true")
+		})
+
+		it("contributes configuration", func() {
+			Expect(g.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:+PrintGCDetails -XX:+PrintGCDateStamps -Xloggc:" + filepath.Join(os.TempDir(), "gc.log") +
+					" -XX:+UseGCLogFileRotation -XX:NumberOfGCLogFiles=5 -XX:GCLogFileSize=10M",
+			}))
+		})
+
+		it("contributes configured path, file count and file size", func() {
+			t.Setenv("BPL_JVM_GC_LOG_PATH", "/logs/gc.log")
+			t.Setenv("BPL_JVM_GC_LOG_FILE_COUNT", "3")
+			t.Setenv("BPL_JVM_GC_LOG_FILE_SIZE", "512K")
+
+			Expect(g.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:+PrintGCDetails -XX:+PrintGCDateStamps -Xloggc:/logs/gc.log -XX:+UseGCLogFileRotation -XX:NumberOfGCLogFiles=3 -XX:GCLogFileSize=512K",
+			}))
+		})
+
+		it("does not update JAVA_TOOL_OPTIONS if -Xloggc is configured", func() {
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Xloggc:/logs/gc.log")
+
+			Expect(g.Execute()).To(BeNil())
+		})
+	})
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+const (
+	DefaultGCLogFileCount = 5
+	DefaultGCLogFileSize  = "10M"
+)
+
+type GCLog9 struct {
+	Logger log.Logger
+}
+
+func (g GCLog9) Execute() (map[string]string, error) {
+	if val := sherpa.ResolveBool("BPL_JVM_GC_LOG_ENABLED"); !val {
+		return nil, nil
+	}
+
+	if ok, err := hasJVMOption("-Xlog"); err != nil {
+		return nil, err
+	} else if ok {
+		g.Logger.Body("JVM logging already configured with -Xlog")
+		return nil, nil
+	}
+
+	path, count, size, err := gcLogConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	g.Logger.Bodyf("GC logging enabled to %s, rotating %d files of %s", path, count, size)
+
+	opts := sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ",
+		fmt.Sprintf("-Xlog:gc*:file=%s:time,uptime,level,tags:filecount=%d,filesize=%s", path, count, size))
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": opts}, nil
+}
+
+// gcLogConfiguration returns the GC log path, file count and file size configured by $BPL_JVM_GC_LOG_PATH,
+// $BPL_JVM_GC_LOG_FILE_COUNT and $BPL_JVM_GC_LOG_FILE_SIZE.
+func gcLogConfiguration() (string, int, calc.Size, error) {
+	path := sherpa.GetEnvWithDefault("BPL_JVM_GC_LOG_PATH", filepath.Join(os.TempDir(), "gc.log"))
+	if strings.ContainsAny(path, " \t:") {
+		return "", 0, calc.Size{}, fmt.Errorf("$BPL_JVM_GC_LOG_PATH=%s must not contain whitespace or colons", path)
+	}
+
+	count := DefaultGCLogFileCount
+	if s, ok := os.LookupEnv("BPL_JVM_GC_LOG_FILE_COUNT"); ok {
+		var err error
+		if count, err = strconv.Atoi(s); err != nil || count < 1 {
+			return "", 0, calc.Size{}, fmt.Errorf("unable to parse $BPL_JVM_GC_LOG_FILE_COUNT=%s as a positive integer", s)
+		}
+	}
+
+	s := sherpa.GetEnvWithDefault("BPL_JVM_GC_LOG_FILE_SIZE", DefaultGCLogFileSize)
+	size, err := calc.ParseSize(s)
+	if err != nil {
+		return "", 0, calc.Size{}, fmt.Errorf("unable to parse $BPL_JVM_GC_LOG_FILE_SIZE=%s\n%w", s, err)
+	}
+	if size.Value < calc.Kibi {
+		return "", 0, calc.Size{}, fmt.Errorf("$BPL_JVM_GC_LOG_FILE_SIZE=%s must be at least 1K", s)
+	}
+
+	return path, count, size, nil
+}
+
+// hasJVMOption returns whether $JAVA_TOOL_OPTIONS contains an option starting with prefix.
+func hasJVMOption(prefix string) (bool, error) {
+	p, err := shellwords.Parse(sherpa.GetEnvWithDefault("JAVA_TOOL_OPTIONS", ""))
+	if err != nil {
+		return false, fmt.Errorf("unable to parse $JAVA_TOOL_OPTIONS\n%w", err)
+	}
+
+	for _, s := range p {
+		if strings.HasPrefix(s, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testGCLog9(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+		g      = helper.GCLog9{Logger: log.NewPaketoLogger(io.Discard)}
+	)
+
+	it("does nothing if $BPL_JVM_GC_LOG_ENABLED is not set", func() {
+		Expect(g.Execute()).To(BeNil())
+	})
+
+	context("$BPL_JVM_GC_LOG_ENABLED", func() {
+		it.Before(func() {
+			t.Setenv("BPL_JVM_GC_LOG_ENABLED", "true")
+		})
+
+		it("contributes configuration", func() {
+			Expect(g.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xlog:gc*:file=" + filepath.Join(os.TempDir(), "gc.log") + ":time,uptime,level,tags:filecount=5,filesize=10M",
+			}))
+		})
+
+		it("contributes configured path, file count and file size", func() {
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Xss512k")
+			t.Setenv("BPL_JVM_GC_LOG_PATH", "/logs/gc.log")
+			t.Setenv("BPL_JVM_GC_LOG_FILE_COUNT", "10")
+			t.Setenv("BPL_JVM_GC_LOG_FILE_SIZE", "1024K")
+
+			Expect(g.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xss512k -Xlog:gc*:file=/logs/gc.log:time,uptime,level,tags:filecount=10,filesize=1M",
+			}))
+		})
+
+		it("does not update JAVA_TOOL_OPTIONS if -Xlog is configured", func() {
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Xlog:safepoint")
+
+			Expect(g.Execute()).To(BeNil())
+		})
+
+		it("returns error for an invalid file count", func() {
+			t.Setenv("BPL_JVM_GC_LOG_FILE_COUNT", "0")
+
+			_, err := g.Execute()
+			Expect(err).To(MatchError("unable to parse $BPL_JVM_GC_LOG_FILE_COUNT=0 as a positive integer"))
+		})
+
+		it("returns error for an invalid file size", func() {
+			t.Setenv("BPL_JVM_GC_LOG_FILE_SIZE", "ten")
+
+			_, err := g.Execute()
+			Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_GC_LOG_FILE_SIZE=ten")))
+		})
+
+		it("returns error for a path with colons", func() {
+			t.Setenv("BPL_JVM_GC_LOG_PATH", "/logs/gc:1.log")
+
+			_, err := g.Execute()
+			Expect(err).To(MatchError("$BPL_JVM_GC_LOG_PATH=/logs/gc:1.log must not contain whitespace or colons"))
+		})
+	})
+}
@@ -26,7 +26,9 @@ import (
 func TestUnit(t *testing.T) {
 	suite := spec.New("jvm-vendors/helper", spec.Report(report.Terminal{}))
 	suite("ActiveProcessorCount", testActiveProcessorCount)
+	suite("JavaAgents", testJavaAgents)
 	suite("JavaOpts", testJavaOpts)
+	suite("JVMGC", testJVMGC)
 	suite("JVMHeapDump", testJVMHeapDump)
 	suite("LinkLocalDNS", testLinkLocalDNS)
 	suite("MemoryCalculator", testMemoryCalculator)
@@ -34,10 +36,16 @@ func TestUnit(t *testing.T) {
 	suite("SecurityProvidersClasspath8", testSecurityProvidersClasspath8)
 	suite("SecurityProvidersClasspath9", testSecurityProvidersClasspath9)
 	suite("SecurityProvidersConfigurer", testSecurityProvidersConfigurer)
+	suite("DirectMemory", testDirectMemory)
 	suite("Debug8", testDebug8)
 	suite("Debug9", testDebug9)
+	suite("GCLog8", testGCLog8)
+	suite("GCLog9", testGCLog9)
 	suite("JMX", testJMX)
 	suite("NMT", testNMT)
 	suite("JFR", testJFR)
+	suite("TLSClientKeystore", testTLSClientKeystore)
+	suite("TruststoreInspector", testTruststoreInspector)
+	suite("ThreadCount", testThreadCount)
 	suite.Run(t)
 }
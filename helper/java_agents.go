@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/magiconair/properties"
+	"github.com/mattn/go-shellwords"
+	"github.com/paketo-buildpacks/libpak/v2/bindings"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+)
+
+const (
+	JavaAgentBindingType = "java-agent"
+
+	// JavaAgentPropertiesKey is the optional properties file of a java-agent binding whose entries are passed to the
+	// agent as its options.
+	JavaAgentPropertiesKey = "agent.properties"
+)
+
+// JavaAgents attaches the agent jar, with -javaagent, or native agent library, with -agentpath, of each binding of type
+// java-agent. Agents are attached in order of binding name. It runs before the memory calculator, so that the classes
+// of agent jars are counted.
+type JavaAgents struct {
+	Bindings libcnb.Bindings
+	Logger   log.Logger
+}
+
+func (j JavaAgents) Execute() (map[string]string, error) {
+	b := bindings.Resolve(j.Bindings, bindings.OfType(JavaAgentBindingType))
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	slices.SortFunc(b, func(a, b libcnb.Binding) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	p, err := shellwords.Parse(sherpa.GetEnvWithDefault("JAVA_TOOL_OPTIONS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse $JAVA_TOOL_OPTIONS\n%w", err)
+	}
+
+	var flags []string
+	for _, binding := range b {
+		flag, err := javaAgentFlag(binding)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(p, flag) || slices.Contains(flags, flag) {
+			continue
+		}
+
+		j.Logger.Bodyf("Attaching Java agent from binding %s", binding.Name)
+		flags = append(flags, flag)
+	}
+
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", flags...)}, nil
+}
+
+// javaAgentFlag returns the flag attaching the single agent jar or native agent library of a binding, with the
+// entries of its properties file as options.
+func javaAgentFlag(binding libcnb.Binding) (string, error) {
+	var agents []string
+	for k := range binding.Secret {
+		if ext := filepath.Ext(k); ext == ".jar" || ext == ".so" {
+			agents = append(agents, k)
+		}
+	}
+	if len(agents) != 1 {
+		return "", fmt.Errorf("binding %s must contain exactly one agent jar or native agent library, found %d", binding.Name, len(agents))
+	}
+
+	path, _ := binding.SecretFilePath(agents[0])
+	flag := fmt.Sprintf("-javaagent:%s", path)
+	if filepath.Ext(path) == ".so" {
+		flag = fmt.Sprintf("-agentpath:%s", path)
+	}
+
+	if s, ok := binding.Secret[JavaAgentPropertiesKey]; ok {
+		l := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+		props, err := l.LoadBytes([]byte(s))
+		if err != nil {
+			return "", fmt.Errorf("unable to parse %s of binding %s\n%w", JavaAgentPropertiesKey, binding.Name, err)
+		}
+
+		keys := props.Keys()
+		slices.Sort(keys)
+
+		var options []string
+		for _, k := range keys {
+			o := fmt.Sprintf("%s=%s", k, props.GetString(k, ""))
+			if strings.ContainsAny(o, " \t\n") {
+				return "", fmt.Errorf("option %s of binding %s must not contain whitespace", o, binding.Name)
+			}
+			options = append(options, o)
+		}
+
+		if len(options) > 0 {
+			flag = fmt.Sprintf("%s=%s", flag, strings.Join(options, ","))
+		}
+	}
+
+	return flag, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testJavaAgents(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		root string
+	)
+
+	binding := func(name string, files map[string]string) libcnb.Binding {
+		path := filepath.Join(root, name)
+		Expect(os.MkdirAll(path, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "type"), []byte(helper.JavaAgentBindingType), 0644)).To(Succeed())
+		for k, v := range files {
+			Expect(os.WriteFile(filepath.Join(path, k), []byte(v), 0644)).To(Succeed())
+		}
+
+		b, err := libcnb.NewBindingFromPath(path)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	it.Before(func() {
+		root = t.TempDir()
+	})
+
+	it("does nothing without java-agent bindings", func() {
+		j := helper.JavaAgents{Logger: log.NewDiscardLogger()}
+
+		Expect(j.Execute()).To(BeNil())
+	})
+
+	it("attaches an agent jar", func() {
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Xss512k")
+
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{binding("otel", map[string]string{"opentelemetry-javaagent.jar": ""})},
+			Logger:   log.NewDiscardLogger(),
+		}
+
+		Expect(j.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": fmt.Sprintf("-Xss512k -javaagent:%s", filepath.Join(root, "otel", "opentelemetry-javaagent.jar")),
+		}))
+	})
+
+	it("attaches a native agent library with options from agent.properties", func() {
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{binding("async-profiler", map[string]string{
+				"libasyncProfiler.so": "",
+				"agent.properties":    "start=true\nevent=cpu\nfile=/tmp/profile.html\n",
+			})},
+			Logger: log.NewDiscardLogger(),
+		}
+
+		Expect(j.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": fmt.Sprintf("-agentpath:%s=event=cpu,file=/tmp/profile.html,start=true",
+				filepath.Join(root, "async-profiler", "libasyncProfiler.so")),
+		}))
+	})
+
+	it("attaches agents in order of binding name", func() {
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{
+				binding("20-apm", map[string]string{"apm.jar": ""}),
+				binding("10-otel", map[string]string{"otel.jar": ""}),
+			},
+			Logger: log.NewDiscardLogger(),
+		}
+
+		Expect(j.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": fmt.Sprintf("-javaagent:%s -javaagent:%s",
+				filepath.Join(root, "10-otel", "otel.jar"), filepath.Join(root, "20-apm", "apm.jar")),
+		}))
+	})
+
+	it("does not attach an agent already in $JAVA_TOOL_OPTIONS", func() {
+		t.Setenv("JAVA_TOOL_OPTIONS", fmt.Sprintf("-javaagent:%s", filepath.Join(root, "otel", "otel.jar")))
+
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{binding("otel", map[string]string{"otel.jar": ""})},
+			Logger:   log.NewDiscardLogger(),
+		}
+
+		Expect(j.Execute()).To(BeNil())
+	})
+
+	it("returns error if the binding has no agent", func() {
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{binding("otel", map[string]string{"agent.properties": "a=b"})},
+			Logger:   log.NewDiscardLogger(),
+		}
+
+		_, err := j.Execute()
+		Expect(err).To(MatchError("binding otel must contain exactly one agent jar or native agent library, found 0"))
+	})
+
+	it("returns error if an option contains whitespace", func() {
+		j := helper.JavaAgents{
+			Bindings: libcnb.Bindings{binding("otel", map[string]string{"otel.jar": "", "agent.properties": "a=b c"})},
+			Logger:   log.NewDiscardLogger(),
+		}
+
+		_, err := j.Execute()
+		Expect(err).To(MatchError("option a=b c of binding otel must not contain whitespace"))
+	})
+}
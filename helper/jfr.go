@@ -20,13 +20,49 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/bindings"
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/paketo-buildpacks/libpak/v2/sherpa"
 )
 
+const JFRBindingType = "jfr"
+
+var (
+	// JFRStartFlightRecordingOptions are the options of -XX:StartFlightRecording and the Java version that added them.
+	JFRStartFlightRecordingOptions = map[string]int{"delay": 8, "disk": 8, "dumponexit": 8, "duration": 8, "filename": 8,
+		"maxage": 8, "maxsize": 8, "name": 8, "path-to-gc-roots": 8, "report-on-exit": 25, "settings": 8}
+
+	// JFRFlightRecorderOptions are the options of -XX:FlightRecorderOptions and the Java version that added them.
+	JFRFlightRecorderOptions = map[string]int{"globalbuffersize": 8, "maxchunksize": 8, "memorysize": 8,
+		"numglobalbuffers": 8, "old-object-queue-size": 8, "preserve-repository": 21, "repository": 8, "retransform": 8,
+		"samplethreads": 8, "stackdepth": 8, "threadbuffersize": 8}
+
+	// jfrSettingRE matches the options of a .jfc file, such as gc=high, and jfrEventSettingRE the [+]event#setting keys
+	// that add or override an event setting, such as +jdk.ObjectAllocationSample#enabled=true.
+	jfrSettingRE      = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	jfrEventSettingRE = regexp.MustCompile(`^\+?[\w.$]+#[\w-]+$`)
+
+	jfrBooleanOptions = []string{"disk", "dumponexit", "path-to-gc-roots", "preserve-repository", "retransform",
+		"samplethreads"}
+	jfrSizeOptions = []string{"globalbuffersize", "maxchunksize", "maxsize", "memorysize", "threadbuffersize"}
+	jfrTimeOptions = []string{"delay", "duration", "maxage"}
+
+	jfrSizeRE = regexp.MustCompile(`^\d+[kKmMgG]?$`)
+	jfrTimeRE = regexp.MustCompile(`^\d+(ns|us|ms|s|m|h|d)?$`)
+)
+
+// JFR starts a flight recording. Its settings are either the JVM's default or profile settings, a .jfc file of a
+// binding of type jfr, or a path. Options are validated, so that a typo fails with an explanation rather than the JVM
+// refusing to start.
 type JFR struct {
-	Logger log.Logger
+	Bindings libcnb.Bindings
+	Logger   log.Logger
 }
 
 func (j JFR) Execute() (map[string]string, error) {
@@ -34,16 +70,191 @@ func (j JFR) Execute() (map[string]string, error) {
 		return nil, nil
 	}
 
-	var argList string
-	if argList = sherpa.GetEnvWithDefault("BPL_JFR_ARGS", ""); argList == "" {
-		argList = fmt.Sprintf("dumponexit=true,filename=%s", filepath.Join(os.TempDir(), "recording.jfr"))
+	javaVersion := 0
+	if v, err := semver.NewVersion(os.Getenv("BPI_JVM_VERSION")); err == nil {
+		javaVersion = int(v.Major())
+	}
+
+	// from Java 17, the options of the .jfc settings file and its event settings are options of the recording
+	recording, err := parseJFROptions("BPL_JFR_ARGS", sherpa.GetEnvWithDefault("BPL_JFR_ARGS", ""),
+		JFRStartFlightRecordingOptions, javaVersion, javaVersion == 0 || javaVersion >= 17)
+	if err != nil {
+		return nil, err
+	}
+
+	dumpPath, configured := os.LookupEnv("BPL_JFR_DUMP_PATH")
+	if configured = configured && dumpPath != ""; configured {
+		if err := os.MkdirAll(dumpPath, 0755); err != nil {
+			return nil, fmt.Errorf("unable to create JFR dump path %s\n%w", dumpPath, err)
+		}
+	} else {
+		dumpPath = os.TempDir()
+	}
+
+	// without arguments, the recording is dumped on exit
+	defaults := len(recording) == 0
+	if defaults {
+		recording = append(recording, "dumponexit=true")
+	}
+	if defaults || configured {
+		recording = appendJFROption(recording, "filename", filepath.Join(dumpPath, "recording.jfr"))
 	}
-	j.Logger.Body("Enabling Java Flight Recorder with args: %s", argList)
+
+	settings, err := j.settings()
+	if err != nil {
+		return nil, err
+	}
+	recording = appendJFROption(recording, "settings", settings)
+
+	for _, o := range []struct{ env, key string }{{"BPL_JFR_MAX_AGE", "maxage"}, {"BPL_JFR_MAX_SIZE", "maxsize"}} {
+		if s, ok := os.LookupEnv(o.env); ok && s != "" {
+			if err := validateJFROption(o.env, o.key, s); err != nil {
+				return nil, err
+			}
+			recording = appendJFROption(recording, o.key, s)
+		}
+	}
+
+	recorder, err := parseJFROptions("BPL_JFR_OPTIONS", sherpa.GetEnvWithDefault("BPL_JFR_OPTIONS", ""),
+		JFRFlightRecorderOptions, javaVersion, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if repository, ok := os.LookupEnv("BPL_JFR_REPOSITORY"); ok && repository != "" {
+		if err := os.MkdirAll(repository, 0755); err != nil {
+			return nil, fmt.Errorf("unable to create JFR repository %s\n%w", repository, err)
+		}
+		recorder = appendJFROption(recorder, "repository", repository)
+	}
+
+	argList := strings.Join(recording, ",")
+	j.Logger.Bodyf("Enabling Java Flight Recorder with args: %s", argList)
 
 	// minimum flag to enable JFR, with default config args
-	jfrConfig := fmt.Sprintf("-XX:StartFlightRecording=%s", argList)
+	opts := []string{fmt.Sprintf("-XX:StartFlightRecording=%s", argList)}
+
+	if len(recorder) > 0 {
+		j.Logger.Bodyf("Configuring Java Flight Recorder with options: %s", strings.Join(recorder, ","))
+		opts = append([]string{fmt.Sprintf("-XX:FlightRecorderOptions=%s", strings.Join(recorder, ","))}, opts...)
+	}
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", opts...)}, nil
+}
 
-	opts := sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", jfrConfig)
+// settings returns the settings configured by $BPL_JFR_SETTINGS, or the only .jfc file of the jfr bindings if it is
+// not set.
+func (j JFR) settings() (string, error) {
+	b := bindings.Resolve(j.Bindings, bindings.OfType(JFRBindingType))
+	slices.SortFunc(b, func(a, b libcnb.Binding) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
-	return map[string]string{"JAVA_TOOL_OPTIONS": opts}, nil
+	s, ok := os.LookupEnv("BPL_JFR_SETTINGS")
+	if !ok || s == "" {
+		var files []string
+		for _, binding := range b {
+			for k := range binding.Secret {
+				if filepath.Ext(k) == ".jfc" {
+					path, _ := binding.SecretFilePath(k)
+					files = append(files, path)
+				}
+			}
+		}
+
+		if len(files) > 1 {
+			slices.Sort(files)
+			return "", fmt.Errorf("jfr bindings contain multiple .jfc files %s, select one with $BPL_JFR_SETTINGS", strings.Join(files, ", "))
+		} else if len(files) == 1 {
+			j.Logger.Bodyf("Using JFR settings %s", files[0])
+			return files[0], nil
+		}
+		return "", nil
+	}
+
+	if s == "default" || s == "profile" {
+		return s, nil
+	}
+
+	for _, binding := range b {
+		for _, name := range []string{s, fmt.Sprintf("%s.jfc", s)} {
+			if path, ok := binding.SecretFilePath(name); ok {
+				j.Logger.Bodyf("Using JFR settings %s from binding %s", name, binding.Name)
+				return path, nil
+			}
+		}
+	}
+
+	if strings.Contains(s, ",") {
+		return "", fmt.Errorf("$BPL_JFR_SETTINGS=%s must not contain commas", s)
+	}
+	if _, err := os.Stat(s); err != nil {
+		return "", fmt.Errorf("unable to find $BPL_JFR_SETTINGS=%s, expected default, profile, a .jfc file of a jfr binding or a path\n%w", s, err)
+	}
+
+	return s, nil
+}
+
+// parseJFROptions splits a comma separated list of options, validating each against the options known to javaVersion,
+// or to the latest Java version if it is unknown. If settings is true, unknown options are passed to the .jfc settings
+// file.
+func parseJFROptions(env string, s string, known map[string]int, javaVersion int, settings bool) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var available []string
+	for k, since := range known {
+		if javaVersion == 0 || javaVersion >= since {
+			available = append(available, k)
+		}
+	}
+	slices.Sort(available)
+
+	options := strings.Split(s, ",")
+	for _, o := range options {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok {
+			return nil, fmt.Errorf("unable to parse $%s, expected key=value but found %q", env, o)
+		}
+		if !slices.Contains(available, k) {
+			if since, ok := known[k]; ok {
+				return nil, fmt.Errorf("unable to parse $%s, option %q requires Java %d or later", env, k, since)
+			}
+			if settings && (jfrSettingRE.MatchString(k) || jfrEventSettingRE.MatchString(k)) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to parse $%s, unknown option %q, expected one of %s", env, k, strings.Join(available, ", "))
+		}
+		if err := validateJFROption(env, k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+func validateJFROption(env string, key string, value string) error {
+	valid := true
+	switch {
+	case slices.Contains(jfrBooleanOptions, key):
+		valid = value == "true" || value == "false"
+	case slices.Contains(jfrSizeOptions, key):
+		valid = jfrSizeRE.MatchString(value)
+	case slices.Contains(jfrTimeOptions, key):
+		valid = jfrTimeRE.MatchString(value)
+	}
+
+	if !valid {
+		return fmt.Errorf("unable to parse $%s, invalid value %q for option %s", env, value, key)
+	}
+	return nil
+}
+
+// appendJFROption appends key=value unless value is empty or options already contain key.
+func appendJFROption(options []string, key string, value string) []string {
+	if value == "" || slices.ContainsFunc(options, func(o string) bool { return strings.HasPrefix(o, key+"=") }) {
+		return options
+	}
+	return append(options, fmt.Sprintf("%s=%s", key, value))
 }
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/buildpacks/libcnb/v2"
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/sclevine/spec"
@@ -34,9 +35,13 @@ func testJFR(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
-		jfr = helper.JFR{Logger: log.NewPaketoLogger(io.Discard)}
+		jfr helper.JFR
 	)
 
+	it.Before(func() {
+		jfr = helper.JFR{Logger: log.NewPaketoLogger(io.Discard)}
+	})
+
 	it("returns if $BPL_JFR_ENABLED is not set", func() {
 		Expect(jfr.Execute()).To(BeNil())
 	})
@@ -75,5 +80,149 @@ func testJFR(t *testing.T, context spec.G, it spec.S) {
 				}))
 			})
 		})
+
+		it("returns error for unknown options in $BPL_JFR_ARGS", func() {
+			t.Setenv("BPL_JFR_ARGS", "filename=/tmp/test.jfr,dumpOnExit=true")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(HavePrefix(`unable to parse $BPL_JFR_ARGS, unknown option "dumpOnExit", expected one of`)))
+		})
+
+		it("returns error for invalid values in $BPL_JFR_ARGS", func() {
+			t.Setenv("BPL_JFR_ARGS", "maxage=1 day")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(`unable to parse $BPL_JFR_ARGS, invalid value "1 day" for option maxage`))
+		})
+
+		it("accepts .jfc options and event settings in $BPL_JFR_ARGS on Java 17 and later", func() {
+			t.Setenv("BPI_JVM_VERSION", "17.0.10")
+			t.Setenv("BPL_JFR_ARGS", "filename=/tmp/test.jfr,gc=high,method-profiling=max,+jdk.ObjectAllocationSample#enabled=true")
+
+			Expect(jfr.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:StartFlightRecording=filename=/tmp/test.jfr,gc=high,method-profiling=max,+jdk.ObjectAllocationSample#enabled=true",
+			}))
+		})
+
+		it("returns error for .jfc options in $BPL_JFR_ARGS before Java 17", func() {
+			t.Setenv("BPI_JVM_VERSION", "11.0.22")
+			t.Setenv("BPL_JFR_ARGS", "gc=high")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(HavePrefix(`unable to parse $BPL_JFR_ARGS, unknown option "gc", expected one of`)))
+		})
+
+		it("returns error for options of later Java versions", func() {
+			t.Setenv("BPI_JVM_VERSION", "21.0.2")
+			t.Setenv("BPL_JFR_ARGS", "report-on-exit=jvm-information")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(`unable to parse $BPL_JFR_ARGS, option "report-on-exit" requires Java 25 or later`))
+		})
+
+		it("contributes named settings, dump path and rotation", func() {
+			dumpPath := filepath.Join(t.TempDir(), "dumps")
+			t.Setenv("BPL_JFR_SETTINGS", "profile")
+			t.Setenv("BPL_JFR_DUMP_PATH", dumpPath)
+			t.Setenv("BPL_JFR_MAX_AGE", "6h")
+			t.Setenv("BPL_JFR_MAX_SIZE", "512m")
+
+			Expect(jfr.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": fmt.Sprintf("-XX:StartFlightRecording=dumponexit=true,filename=%s,settings=profile,maxage=6h,maxsize=512m",
+					filepath.Join(dumpPath, "recording.jfr")),
+			}))
+			Expect(dumpPath).To(BeADirectory())
+		})
+
+		it("does not override $BPL_JFR_ARGS", func() {
+			t.Setenv("BPL_JFR_ARGS", "filename=/tmp/test.jfr,settings=default")
+			t.Setenv("BPL_JFR_SETTINGS", "profile")
+
+			Expect(jfr.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:StartFlightRecording=filename=/tmp/test.jfr,settings=default",
+			}))
+		})
+
+		it("returns error for settings that do not exist", func() {
+			t.Setenv("BPL_JFR_SETTINGS", "/does/not/exist.jfc")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(HavePrefix("unable to find $BPL_JFR_SETTINGS=/does/not/exist.jfc")))
+		})
+
+		it("contributes repository and recorder options", func() {
+			repository := filepath.Join(t.TempDir(), "repository")
+			t.Setenv("BPL_JFR_OPTIONS", "stackdepth=128")
+			t.Setenv("BPL_JFR_REPOSITORY", repository)
+
+			Expect(jfr.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": fmt.Sprintf("-XX:FlightRecorderOptions=stackdepth=128,repository=%s -XX:StartFlightRecording=dumponexit=true,filename=%s",
+					repository, filepath.Join(os.TempDir(), "recording.jfr")),
+			}))
+			Expect(repository).To(BeADirectory())
+		})
+
+		it("returns error for unknown options in $BPL_JFR_OPTIONS", func() {
+			t.Setenv("BPL_JFR_OPTIONS", "stack-depth=128")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(HavePrefix(`unable to parse $BPL_JFR_OPTIONS, unknown option "stack-depth"`)))
+		})
+
+		it("validates $BPL_JFR_OPTIONS against the Java version", func() {
+			t.Setenv("BPI_JVM_VERSION", "11.0.22")
+			t.Setenv("BPL_JFR_OPTIONS", "samplethreads=true")
+
+			Expect(jfr.Execute()).To(HaveKeyWithValue("JAVA_TOOL_OPTIONS", HavePrefix("-XX:FlightRecorderOptions=samplethreads=true ")))
+
+			t.Setenv("BPL_JFR_OPTIONS", "preserve-repository=true")
+
+			_, err := jfr.Execute()
+			Expect(err).To(MatchError(`unable to parse $BPL_JFR_OPTIONS, option "preserve-repository" requires Java 21 or later`))
+		})
+
+		context("jfr binding", func() {
+			var bindingPath string
+
+			binding := func() libcnb.Bindings {
+				b, err := libcnb.NewBindingFromPath(bindingPath)
+				Expect(err).NotTo(HaveOccurred())
+				return libcnb.Bindings{b}
+			}
+
+			it.Before(func() {
+				bindingPath = t.TempDir()
+				Expect(os.WriteFile(filepath.Join(bindingPath, "type"), []byte(helper.JFRBindingType), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingPath, "custom.jfc"), []byte("<configuration/>"), 0644)).To(Succeed())
+			})
+
+			it("uses the only .jfc file", func() {
+				jfr.Bindings = binding()
+
+				Expect(jfr.Execute()).To(Equal(map[string]string{
+					"JAVA_TOOL_OPTIONS": fmt.Sprintf("-XX:StartFlightRecording=dumponexit=true,filename=%s,settings=%s",
+						filepath.Join(os.TempDir(), "recording.jfr"), filepath.Join(bindingPath, "custom.jfc")),
+				}))
+			})
+
+			it("uses the .jfc file named by $BPL_JFR_SETTINGS", func() {
+				Expect(os.WriteFile(filepath.Join(bindingPath, "other.jfc"), []byte("<configuration/>"), 0644)).To(Succeed())
+				t.Setenv("BPL_JFR_SETTINGS", "custom")
+				jfr.Bindings = binding()
+
+				Expect(jfr.Execute()).To(Equal(map[string]string{
+					"JAVA_TOOL_OPTIONS": fmt.Sprintf("-XX:StartFlightRecording=dumponexit=true,filename=%s,settings=%s",
+						filepath.Join(os.TempDir(), "recording.jfr"), filepath.Join(bindingPath, "custom.jfc")),
+				}))
+			})
+
+			it("returns error for multiple .jfc files", func() {
+				Expect(os.WriteFile(filepath.Join(bindingPath, "other.jfc"), []byte("<configuration/>"), 0644)).To(Succeed())
+				jfr.Bindings = binding()
+
+				_, err := jfr.Execute()
+				Expect(err).To(MatchError(HaveSuffix("select one with $BPL_JFR_SETTINGS")))
+			})
+		})
 	})
 }
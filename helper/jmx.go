@@ -17,14 +17,35 @@
 package helper
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/bindings"
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
+const JMXBindingType = "jmx"
+
+var (
+	TmpJMXPasswordFile = filepath.Join(os.TempDir(), "jmxremote.password")
+	TmpJMXKeyStore     = filepath.Join(os.TempDir(), "jmx-keystore.p12")
+	TmpJMXTrustStore   = filepath.Join(os.TempDir(), "jmx-truststore.p12")
+	TmpJMXSSLConfig    = filepath.Join(os.TempDir(), "jmxremote-ssl.properties")
+)
+
+// JMX enables remote JMX. A binding of type jmx configures authentication with its jmxremote.password and
+// jmxremote.access files, and TLS with its tls.crt and tls.key, requiring client certificates signed by its ca.crt.
+// JMX without authentication is only enabled if it is limited to the local host or explicitly allowed.
 type JMX struct {
-	Logger log.Logger
+	Bindings libcnb.Bindings
+	Logger   log.Logger
 }
 
 func (j JMX) Execute() (map[string]string, error) {
@@ -33,14 +54,174 @@ func (j JMX) Execute() (map[string]string, error) {
 	}
 
 	port := sherpa.GetEnvWithDefault("BPL_JMX_PORT", "5000")
+	host := sherpa.GetEnvWithDefault("BPL_JMX_HOST", "127.0.0.1")
+	localOnly := sherpa.ResolveBool("BPL_JMX_LOCAL_ONLY")
+
+	b, ok, err := bindings.ResolveOne(j.Bindings, bindings.OfType(JMXBindingType))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve binding %s\n%w", JMXBindingType, err)
+	}
+
+	var (
+		authenticate bool
+		ssl          []string
+		auth         []string
+	)
+	if ok {
+		if auth, err = j.authentication(b); err != nil {
+			return nil, err
+		}
+		if ssl, err = j.ssl(b); err != nil {
+			return nil, err
+		}
+		_, clientAuth := b.SecretFilePath("ca.crt")
+		authenticate = len(auth) > 0 || (len(ssl) > 0 && clientAuth)
+	}
 
-	j.Logger.Body("JMX enabled on port %s", port)
+	if !authenticate && !localOnly && !sherpa.ResolveBool("BPL_JMX_ALLOW_UNAUTHENTICATED") {
+		return nil, fmt.Errorf("refusing to enable JMX without authentication for remote connections, " +
+			"$BPL_JMX_ENABLED=true no longer enables unauthenticated remote JMX on its own: bind a jmx binding with " +
+			"credentials, set $BPL_JMX_LOCAL_ONLY=true to only listen on the local host, or set " +
+			"$BPL_JMX_ALLOW_UNAUTHENTICATED=true to keep the previous behavior")
+	}
+
+	j.Logger.Bodyf("JMX enabled on port %s", port)
+	if !authenticate {
+		j.Logger.Body("WARNING: JMX is enabled without authentication")
+	}
 
-	opts := sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", "-Djava.rmi.server.hostname=127.0.0.1",
-		"-Dcom.sun.management.jmxremote.authenticate=false",
-		"-Dcom.sun.management.jmxremote.ssl=false",
+	opts := []string{
+		fmt.Sprintf("-Djava.rmi.server.hostname=%s", host),
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.authenticate=%t", len(auth) > 0),
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.ssl=%t", len(ssl) > 0),
 		fmt.Sprintf("-Dcom.sun.management.jmxremote.port=%s", port),
-		fmt.Sprintf("-Dcom.sun.management.jmxremote.rmi.port=%s", port))
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.rmi.port=%s", port),
+	}
+	if localOnly {
+		opts = append(opts, "-Dcom.sun.management.jmxremote.host=127.0.0.1")
+	}
+	opts = append(opts, auth...)
+	opts = append(opts, ssl...)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", opts...)}, nil
+}
+
+// authentication returns the options configuring the password and access files of a binding. The password file is
+// copied as the JVM requires it to be readable only by its owner, which a mounted binding often is not.
+func (j JMX) authentication(b libcnb.Binding) ([]string, error) {
+	passwordFile, ok := b.SecretFilePath("jmxremote.password")
+	if !ok {
+		if _, ok := b.SecretFilePath("jmxremote.access"); ok {
+			return nil, fmt.Errorf("binding %s must contain jmxremote.password with jmxremote.access", b.Name)
+		}
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", passwordFile, err)
+	}
+
+	if err := os.WriteFile(TmpJMXPasswordFile, data, 0600); err != nil {
+		return nil, fmt.Errorf("unable to write %s\n%w", TmpJMXPasswordFile, err)
+	}
+	if err := os.Chmod(TmpJMXPasswordFile, 0600); err != nil {
+		return nil, fmt.Errorf("unable to restrict access to %s\n%w", TmpJMXPasswordFile, err)
+	}
+
+	j.Logger.Bodyf("Using JMX password file from binding %s", b.Name)
+	opts := []string{fmt.Sprintf("-Dcom.sun.management.jmxremote.password.file=%s", TmpJMXPasswordFile)}
+
+	if accessFile, ok := b.SecretFilePath("jmxremote.access"); ok {
+		opts = append(opts, fmt.Sprintf("-Dcom.sun.management.jmxremote.access.file=%s", accessFile))
+	}
+
+	return opts, nil
+}
+
+// ssl returns the options configuring TLS with a keystore built from the tls.crt and tls.key of a binding, and a
+// truststore for client authentication built from its ca.crt. The stores are configured in a JMX specific SSL
+// configuration file, so that they do not replace the application's.
+func (j JMX) ssl(b libcnb.Binding) ([]string, error) {
+	certFile, certOk := b.SecretFilePath("tls.crt")
+	keyFile, keyOk := b.SecretFilePath("tls.key")
+	if !certOk && !keyOk {
+		return nil, nil
+	} else if !certOk || !keyOk {
+		return nil, fmt.Errorf("binding %s must contain tls.crt and tls.key", b.Name)
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := writeKeyStore(b, certFile, keyFile, TmpJMXKeyStore, password)
+	if err != nil {
+		return nil, err
+	}
+	j.Logger.Bodyf("Using JMX server certificate %s from binding %s", cert.Subject, b.Name)
+
+	config := []string{
+		fmt.Sprintf("javax.net.ssl.keyStore=%s", TmpJMXKeyStore),
+		"javax.net.ssl.keyStoreType=PKCS12",
+		fmt.Sprintf("javax.net.ssl.keyStorePassword=%s", password),
+	}
+	opts := []string{
+		"-Dcom.sun.management.jmxremote.registry.ssl=true",
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.ssl.config.file=%s", TmpJMXSSLConfig),
+	}
+
+	if caFile, ok := b.SecretFilePath("ca.crt"); ok {
+		if err := writeTrustStore(caFile, TmpJMXTrustStore, password); err != nil {
+			return nil, err
+		}
+
+		config = append(config,
+			fmt.Sprintf("javax.net.ssl.trustStore=%s", TmpJMXTrustStore),
+			"javax.net.ssl.trustStoreType=PKCS12",
+			fmt.Sprintf("javax.net.ssl.trustStorePassword=%s", password))
+		opts = append(opts, "-Dcom.sun.management.jmxremote.ssl.need.client.auth=true")
+	}
+
+	if err := os.WriteFile(TmpJMXSSLConfig, []byte(strings.Join(config, "\n")+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("unable to write %s\n%w", TmpJMXSSLConfig, err)
+	}
+
+	return opts, nil
+}
+
+// writeTrustStore writes the PEM certificates in caFile to a PKCS12 truststore at path.
+func writeTrustStore(caFile string, path string, password string) error {
+	in, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", caFile, err)
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(in); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("unable to parse certificate from %s\n%w", caFile, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	data, err := pkcs12.Modern2023.EncodeTrustStore(certs, password)
+	if err != nil {
+		return fmt.Errorf("unable to encode truststore %s\n%w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("unable to write truststore %s\n%w", path, err)
+	}
 
-	return map[string]string{"JAVA_TOOL_OPTIONS": opts}, nil
+	return nil
 }
@@ -17,9 +17,21 @@
 package helper_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
 	"io"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/buildpacks/libcnb/v2"
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/libpak/v2/log"
 	"github.com/sclevine/spec"
@@ -31,9 +43,13 @@ func testJMX(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
-		j = helper.JMX{Logger: log.NewPaketoLogger(io.Discard)}
+		j helper.JMX
 	)
 
+	it.Before(func() {
+		j = helper.JMX{Logger: log.NewPaketoLogger(io.Discard)}
+	})
+
 	it("returns if $BPL_JMX_ENABLED is not set", func() {
 		Expect(j.Execute()).To(BeNil())
 	})
@@ -43,33 +59,163 @@ func testJMX(t *testing.T, context spec.G, it spec.S) {
 			t.Setenv("BPL_JMX_ENABLED", "true")
 		})
 
-		it("contributes configuration", func() {
+		it("refuses to enable JMX without authentication", func() {
+			_, err := j.Execute()
+			Expect(err).To(MatchError(HavePrefix("refusing to enable JMX without authentication for remote connections")))
+			Expect(err).To(MatchError(ContainSubstring("bind a jmx binding")))
+			Expect(err).To(MatchError(ContainSubstring("set $BPL_JMX_LOCAL_ONLY=true")))
+		})
+
+		it("contributes local only configuration", func() {
+			t.Setenv("BPL_JMX_LOCAL_ONLY", "true")
+
 			Expect(j.Execute()).To(Equal(map[string]string{
-				"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000",
+				"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000 -Dcom.sun.management.jmxremote.host=127.0.0.1",
 			}))
 		})
 
-		context("$BPL_JMX_PORT", func() {
+		context("$BPL_JMX_ALLOW_UNAUTHENTICATED", func() {
 			it.Before(func() {
-				t.Setenv("BPL_JMX_PORT", "5001")
+				t.Setenv("BPL_JMX_ALLOW_UNAUTHENTICATED", "true")
 			})
 
-			it("contributes port configuration from $BPL_JMX_PORT", func() {
+			it("contributes configuration", func() {
 				Expect(j.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5001 -Dcom.sun.management.jmxremote.rmi.port=5001",
+					"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000",
 				}))
 			})
+
+			context("$BPL_JMX_PORT", func() {
+				it.Before(func() {
+					t.Setenv("BPL_JMX_PORT", "5001")
+				})
+
+				it("contributes port configuration from $BPL_JMX_PORT", func() {
+					Expect(j.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5001 -Dcom.sun.management.jmxremote.rmi.port=5001",
+					}))
+				})
+			})
+
+			context("$BPL_JMX_HOST", func() {
+				it.Before(func() {
+					t.Setenv("BPL_JMX_HOST", "jmx.example.com")
+				})
+
+				it("contributes host configuration from $BPL_JMX_HOST", func() {
+					Expect(j.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-Djava.rmi.server.hostname=jmx.example.com -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000",
+					}))
+				})
+			})
+
+			context("$JAVA_TOOL_OPTIONS", func() {
+				it.Before(func() {
+					t.Setenv("JAVA_TOOL_OPTIONS", "test-java-tool-options")
+				})
+
+				it("contributes configuration appended to existing $JAVA_TOOL_OPTIONS", func() {
+					Expect(j.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "test-java-tool-options -Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000",
+					}))
+				})
+			})
 		})
 
-		context("$JAVA_TOOL_OPTIONS", func() {
+		context("jmx binding", func() {
+			var bindingPath string
+
+			binding := func() libcnb.Bindings {
+				b, err := libcnb.NewBindingFromPath(bindingPath)
+				Expect(err).NotTo(HaveOccurred())
+				return libcnb.Bindings{b}
+			}
+
+			writeCertificate := func(name string, key *ecdsa.PrivateKey, isCA bool) {
+				template := &x509.Certificate{
+					SerialNumber:          big.NewInt(1),
+					Subject:               pkix.Name{CommonName: fmt.Sprintf("test-%s", name)},
+					NotBefore:             time.Now(),
+					NotAfter:              time.Now().Add(time.Hour),
+					IsCA:                  isCA,
+					BasicConstraintsValid: true,
+				}
+				cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.WriteFile(filepath.Join(bindingPath, name), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0644)).To(Succeed())
+			}
+
 			it.Before(func() {
-				t.Setenv("JAVA_TOOL_OPTIONS", "test-java-tool-options")
+				bindingPath = t.TempDir()
+				Expect(os.WriteFile(filepath.Join(bindingPath, "type"), []byte(helper.JMXBindingType), 0644)).To(Succeed())
+			})
+
+			it.After(func() {
+				for _, f := range []string{helper.TmpJMXPasswordFile, helper.TmpJMXKeyStore, helper.TmpJMXTrustStore, helper.TmpJMXSSLConfig} {
+					_ = os.Remove(f)
+				}
+			})
+
+			it("configures authentication from the password and access files", func() {
+				Expect(os.WriteFile(filepath.Join(bindingPath, "jmxremote.password"), []byte("monitorRole secret\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingPath, "jmxremote.access"), []byte("monitorRole readonly\n"), 0644)).To(Succeed())
+
+				j.Bindings = binding()
+				Expect(j.Execute()).To(Equal(map[string]string{
+					"JAVA_TOOL_OPTIONS": fmt.Sprintf("-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=true -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000 -Dcom.sun.management.jmxremote.password.file=%s -Dcom.sun.management.jmxremote.access.file=%s",
+						helper.TmpJMXPasswordFile, filepath.Join(bindingPath, "jmxremote.access")),
+				}))
+
+				fi, err := os.Stat(helper.TmpJMXPasswordFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0600)))
+				Expect(os.ReadFile(helper.TmpJMXPasswordFile)).To(Equal([]byte("monitorRole secret\n")))
 			})
 
-			it("contributes configuration appended to existing $JAVA_TOOL_OPTIONS", func() {
+			it("returns error for an access file without a password file", func() {
+				Expect(os.WriteFile(filepath.Join(bindingPath, "jmxremote.access"), []byte("monitorRole readonly\n"), 0644)).To(Succeed())
+
+				j.Bindings = binding()
+				_, err := j.Execute()
+				Expect(err).To(MatchError(fmt.Sprintf("binding %s must contain jmxremote.password with jmxremote.access", filepath.Base(bindingPath))))
+			})
+
+			it("configures TLS with client authentication", func() {
+				key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				b, err := x509.MarshalPKCS8PrivateKey(key)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.WriteFile(filepath.Join(bindingPath, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}), 0644)).To(Succeed())
+				writeCertificate("tls.crt", key, false)
+				writeCertificate("ca.crt", key, true)
+
+				j.Bindings = binding()
 				Expect(j.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "test-java-tool-options -Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=false -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000",
+					"JAVA_TOOL_OPTIONS": fmt.Sprintf("-Djava.rmi.server.hostname=127.0.0.1 -Dcom.sun.management.jmxremote.authenticate=false -Dcom.sun.management.jmxremote.ssl=true -Dcom.sun.management.jmxremote.port=5000 -Dcom.sun.management.jmxremote.rmi.port=5000 -Dcom.sun.management.jmxremote.registry.ssl=true -Dcom.sun.management.jmxremote.ssl.config.file=%s -Dcom.sun.management.jmxremote.ssl.need.client.auth=true",
+						helper.TmpJMXSSLConfig),
 				}))
+
+				Expect(helper.TmpJMXKeyStore).To(BeARegularFile())
+				Expect(helper.TmpJMXTrustStore).To(BeARegularFile())
+
+				config, err := os.ReadFile(helper.TmpJMXSSLConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(config)).To(ContainSubstring(fmt.Sprintf("javax.net.ssl.keyStore=%s\n", helper.TmpJMXKeyStore)))
+				Expect(string(config)).To(ContainSubstring(fmt.Sprintf("javax.net.ssl.trustStore=%s\n", helper.TmpJMXTrustStore)))
+			})
+
+			it("refuses to enable JMX with TLS but without authentication", func() {
+				key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				b, err := x509.MarshalPKCS8PrivateKey(key)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.WriteFile(filepath.Join(bindingPath, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}), 0644)).To(Succeed())
+				writeCertificate("tls.crt", key, false)
+
+				j.Bindings = binding()
+				_, err = j.Execute()
+				Expect(err).To(MatchError(HavePrefix("refusing to enable JMX without authentication")))
 			})
 		})
 	})
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mattn/go-shellwords"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+// JVMGC selects the GC configured by $BPL_JVM_GC. It runs before the memory calculator, so that the native overhead of
+// the GC it selects is calculated.
+type JVMGC struct {
+	Logger log.Logger
+
+	// ActiveProcessorCount determines the processors available to the JVM in auto mode.
+	ActiveProcessorCount ActiveProcessorCount
+
+	// MemoryCalculator determines the total memory the heap is estimated from in auto mode.
+	MemoryCalculator MemoryCalculator
+}
+
+func (j JVMGC) Execute() (map[string]string, error) {
+	mode, ok := os.LookupEnv("BPL_JVM_GC")
+	if !ok || mode == "" {
+		return nil, nil
+	}
+
+	opts := os.Getenv("JAVA_TOOL_OPTIONS")
+	p, err := shellwords.Parse(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse $JAVA_TOOL_OPTIONS\n%w", err)
+	}
+
+	if i := slices.IndexFunc(p, calc.GCFlagRE.MatchString); i >= 0 {
+		j.Logger.Bodyf("Using the GC selected by %s in $JAVA_TOOL_OPTIONS instead of $BPL_JVM_GC=%s", p[i], mode)
+		return nil, nil
+	}
+
+	javaVersion := 0
+	if v, err := semver.NewVersion(os.Getenv("BPI_JVM_VERSION")); err == nil {
+		javaVersion = int(v.Major())
+	}
+
+	var gc string
+	if strings.EqualFold(mode, "auto") {
+		processors, err := j.ActiveProcessorCount.Count()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine active processor count\n%w", err)
+		}
+		heap := j.estimateHeap(opts, javaVersion)
+		gc = calc.SelectGC(heap, processors, javaVersion)
+		j.Logger.Bodyf("Selected the %s GC for an estimated heap of %s and %d processors", gc, calc.Size{Value: heap}, processors)
+	} else if gc, err = calc.ParseGC(mode); err != nil {
+		return nil, fmt.Errorf("unable to parse $BPL_JVM_GC=%s, expected auto, serial, parallel, g1, zgc or shenandoah\n%w", mode, err)
+	}
+
+	flags, err := calc.GCFlags(gc, javaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select the %s GC\n%w", gc, err)
+	}
+
+	j.Logger.Bodyf("Configuring the %s GC: %s", gc, strings.Join(flags, " "))
+	return map[string]string{"JAVA_TOOL_OPTIONS": sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", flags...)}, nil
+}
+
+// estimateHeap estimates the heap the memory calculator will calculate from the total memory, the class counts of the
+// build and the default thread count, applying the low profile as it would. If the calculation fails, the memory
+// calculator will fail too, and the total memory is returned.
+func (j JVMGC) estimateHeap(opts string, javaVersion int) int64 {
+	m := j.MemoryCalculator
+	m.Logger = log.NewDiscardLogger()
+
+	total, _, err := m.getTotalMemory()
+	if err != nil || total == UnsetTotalMemory {
+		total = calc.Gibi
+	}
+
+	c := calc.Calculator{
+		JavaVersion:      javaVersion,
+		LoadedClassCount: estimateLoadedClassCount(),
+		ThreadCount:      calc.DefaultThreadCountValue,
+		TotalMemory:      calc.Size{Value: min(total, MaxJVMSize)},
+	}
+	if c.TotalMemory.Value < calc.LowProfileThreshold {
+		disabled, err := strconv.ParseBool(os.Getenv("BPL_LOW_MEMORY_PROFILE_DISABLED"))
+		c.LowProfile = err != nil || !disabled
+	}
+	if s, ok := os.LookupEnv("BPL_JVM_HEAD_ROOM"); ok {
+		if a, err := calc.ParseHeadRoom(s); err == nil {
+			c.HeadRoom = a
+		}
+	}
+
+	o, err := c.Calculate(opts)
+	if err != nil {
+		return c.TotalMemory.Value
+	}
+	return o.Memory.Heap.Value
+}
+
+// estimateLoadedClassCount estimates the loaded class count from $BPL_JVM_LOADED_CLASS_COUNT, or the JVM and
+// application class counts of the build, without counting any classes.
+func estimateLoadedClassCount() int {
+	if n, err := strconv.Atoi(os.Getenv("BPL_JVM_LOADED_CLASS_COUNT")); err == nil {
+		return n
+	}
+
+	jvm, _ := strconv.Atoi(os.Getenv("BPI_JVM_CLASS_COUNT"))
+	app, _ := strconv.Atoi(os.Getenv("BPI_APP_CLASS_COUNT"))
+	return int(float64(jvm+app) * ClassLoadFactor)
+}
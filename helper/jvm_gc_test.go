@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testJVMGC(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		b           *bytes.Buffer
+		j           helper.JVMGC
+		memoryLimit string
+	)
+
+	it.Before(func() {
+		b = &bytes.Buffer{}
+		memoryLimit = t.TempDir() + "/memory.limit_in_bytes"
+
+		j = helper.JVMGC{
+			Logger: log.NewPaketoLogger(b),
+			MemoryCalculator: helper.MemoryCalculator{
+				CgroupPath:        t.TempDir() + "/cgroup",
+				CgroupRoot:        t.TempDir(),
+				MemoryLimitPathV1: memoryLimit,
+				MemoryInfoPath:    t.TempDir() + "/meminfo",
+			},
+		}
+	})
+
+	it("does nothing if $BPL_JVM_GC is not set", func() {
+		Expect(j.Execute()).To(BeNil())
+	})
+
+	it("configures the GC in $BPL_JVM_GC", func() {
+		t.Setenv("BPL_JVM_GC", "parallel")
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Xss512k")
+
+		Expect(j.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-Xss512k -XX:+UseParallelGC"}))
+	})
+
+	it("configures generational ZGC on Java 21", func() {
+		t.Setenv("BPL_JVM_GC", "zgc")
+		t.Setenv("BPI_JVM_VERSION", "21.0.2")
+
+		Expect(j.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:+UseZGC -XX:+ZGenerational"}))
+	})
+
+	it("does not override a GC in $JAVA_TOOL_OPTIONS", func() {
+		t.Setenv("BPL_JVM_GC", "auto")
+		t.Setenv("JAVA_TOOL_OPTIONS", "-XX:+UseShenandoahGC")
+
+		Expect(j.Execute()).To(BeNil())
+		Expect(b.String()).To(ContainSubstring("Using the GC selected by -XX:+UseShenandoahGC in $JAVA_TOOL_OPTIONS"))
+	})
+
+	it("returns error for unknown GCs", func() {
+		t.Setenv("BPL_JVM_GC", "cms")
+
+		_, err := j.Execute()
+		Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_GC=cms")))
+	})
+
+	it("returns error for GCs the Java version does not have", func() {
+		t.Setenv("BPL_JVM_GC", "zgc")
+		t.Setenv("BPI_JVM_VERSION", "8.0.452")
+
+		_, err := j.Execute()
+		Expect(err).To(MatchError(ContainSubstring("ZGC requires Java 11 or later")))
+	})
+
+	context("auto", func() {
+		it.Before(func() {
+			t.Setenv("BPL_JVM_GC", "auto")
+			t.Setenv("BPI_JVM_VERSION", "21.0.2")
+			t.Setenv("BPI_JVM_CLASS_COUNT", "1000")
+			t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "4")
+		})
+
+		it("selects Serial for small heaps", func() {
+			Expect(os.WriteFile(memoryLimit, []byte(strconv.FormatInt(512*calc.Mebi, 10)), 0600)).To(Succeed())
+			Expect(j.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:+UseSerialGC"}))
+			Expect(b.String()).To(ContainSubstring("Selected the Serial GC for an estimated heap of "))
+		})
+
+		it("selects ZGC for large heaps", func() {
+			Expect(os.WriteFile(memoryLimit, []byte(strconv.FormatInt(32*calc.Gibi, 10)), 0600)).To(Succeed())
+
+			Expect(j.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:+UseZGC -XX:+ZGenerational"}))
+		})
+
+		it("selects Serial for a single processor", func() {
+			Expect(os.WriteFile(memoryLimit, []byte(strconv.FormatInt(32*calc.Gibi, 10)), 0600)).To(Succeed())
+			t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "1")
+
+			Expect(j.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-XX:+UseSerialGC"}))
+		})
+	})
+}
@@ -20,11 +20,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/mattn/go-shellwords"
 	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
 )
 
 type JVMHeapDump struct {
@@ -43,7 +50,9 @@ func (a JVMHeapDump) Execute() (map[string]string, error) {
 		return nil, fmt.Errorf("unable to create heap dump path %s\n%w", heapDumpPath, err)
 	}
 
-	heapDumpPath = filepath.Join(heapDumpPath, fmt.Sprintf("java_%s.hprof", time.Now().Format(time.RFC3339)))
+	if err := a.prune(heapDumpPath); err != nil {
+		return nil, err
+	}
 
 	var values []string
 	s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS")
@@ -51,36 +60,160 @@ func (a JVMHeapDump) Execute() (map[string]string, error) {
 		values = append(values, s)
 	}
 
-	if p, err := shellwords.Parse(s); err != nil {
+	p, err := shellwords.Parse(s)
+	if err != nil {
 		return nil, fmt.Errorf("unable to parse $JAVA_TOOL_OPTIONS\n%w", err)
-	} else {
-		found := false
-
-		for _, s := range p {
-			if s == "-XX:+HeapDumpOnOutOfMemoryError" {
-				found = true
-				break
-			}
+	}
+
+	hasOption := func(prefix string) bool {
+		return slices.ContainsFunc(p, func(s string) bool { return strings.HasPrefix(s, prefix) })
+	}
+
+	gzipLevel, err := a.gzipLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	// the host name, startup time and pid, which the JVM substitutes for %p, make dumps of replicas and restarts
+	// sharing a volume unique
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "java"
+	}
+	prefix := fmt.Sprintf("%s_%s", host, time.Now().Format(time.RFC3339))
+
+	heapDumpFile := filepath.Join(heapDumpPath, fmt.Sprintf("%s_pid%%p.hprof", prefix))
+	if gzipLevel > 0 {
+		heapDumpFile = fmt.Sprintf("%s.gz", heapDumpFile)
+	}
+
+	if !slices.Contains(p, "-XX:+HeapDumpOnOutOfMemoryError") {
+		a.Logger.Body("Enabling HeapDumpOnOutOfMemoryError")
+		values = append(values, "-XX:+HeapDumpOnOutOfMemoryError")
+	}
+
+	if !hasOption("-XX:HeapDumpPath=") {
+		a.Logger.Bodyf("Setting HeapDumpPath to %s", heapDumpFile)
+		values = append(values, fmt.Sprintf("-XX:HeapDumpPath=%s", heapDumpFile))
+	}
+
+	// applies to a configured HeapDumpPath too, whose default file name the JVM suffixes with .gz when compressing
+	if gzipLevel > 0 && !hasOption("-XX:HeapDumpGzipLevel=") {
+		a.Logger.Bodyf("Setting HeapDumpGzipLevel to %d", gzipLevel)
+		values = append(values, fmt.Sprintf("-XX:HeapDumpGzipLevel=%d", gzipLevel))
+	}
+
+	if sherpa.ResolveBool("BPL_HEAP_DUMP_CRASH_ON_OOM") {
+		if !slices.Contains(p, "-XX:+CrashOnOutOfMemoryError") {
+			a.Logger.Body("Enabling CrashOnOutOfMemoryError")
+			values = append(values, "-XX:+CrashOnOutOfMemoryError")
 		}
 
-		if !found {
-			a.Logger.Body("Enabling HeapDumpOnOutOfMemoryError")
-			values = append(values, "-XX:+HeapDumpOnOutOfMemoryError")
+		if !hasOption("-XX:ErrorFile=") {
+			errorFile := filepath.Join(heapDumpPath, fmt.Sprintf("%s_hs_err_pid%%p.log", prefix))
+			a.Logger.Bodyf("Setting ErrorFile to %s", errorFile)
+			values = append(values, fmt.Sprintf("-XX:ErrorFile=%s", errorFile))
 		}
+	}
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+// gzipLevel returns the compression level configured by $BPL_HEAP_DUMP_GZIP_LEVEL, or 0 if heap dumps are not to be
+// compressed.
+func (a JVMHeapDump) gzipLevel() (int, error) {
+	s, ok := os.LookupEnv("BPL_HEAP_DUMP_GZIP_LEVEL")
+	if !ok || s == "" {
+		return 0, nil
+	}
+
+	level, err := strconv.Atoi(s)
+	if err != nil || level < 0 || level > 9 {
+		return 0, fmt.Errorf("unable to parse $BPL_HEAP_DUMP_GZIP_LEVEL=%s, expected 0 to 9", s)
+	}
 
-		found = false
-		for _, s := range p {
-			if strings.HasPrefix(s, "-XX:HeapDumpPath=") {
-				found = true
-				break
-			}
+	if level > 0 {
+		if v, err := semver.NewVersion(os.Getenv("BPI_JVM_VERSION")); err != nil || v.LessThan(jvmvendors.Java17) {
+			a.Logger.Body("WARNING: Compressed heap dumps require Java 17 or later, ignoring $BPL_HEAP_DUMP_GZIP_LEVEL")
+			return 0, nil
 		}
+	}
+
+	return level, nil
+}
 
-		if !found {
-			a.Logger.Body("Setting HeapDumpPath to %s", heapDumpPath)
-			values = append(values, fmt.Sprintf("-XX:HeapDumpPath=%s", heapDumpPath))
+// prune removes the oldest heap dumps in path beyond the count of $BPL_HEAP_DUMP_RETENTION_COUNT or the total size of
+// $BPL_HEAP_DUMP_RETENTION_SIZE, and likewise the oldest error files of $BPL_HEAP_DUMP_CRASH_ON_OOM.
+func (a JVMHeapDump) prune(path string) error {
+	count := -1
+	if s, ok := os.LookupEnv("BPL_HEAP_DUMP_RETENTION_COUNT"); ok && s != "" {
+		var err error
+		if count, err = strconv.Atoi(s); err != nil || count < 0 {
+			return fmt.Errorf("unable to parse $BPL_HEAP_DUMP_RETENTION_COUNT=%s as a non-negative integer", s)
 		}
 	}
 
-	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+	size := int64(-1)
+	if s, ok := os.LookupEnv("BPL_HEAP_DUMP_RETENTION_SIZE"); ok && s != "" {
+		sz, err := calc.ParseSize(s)
+		if err != nil {
+			return fmt.Errorf("unable to parse $BPL_HEAP_DUMP_RETENTION_SIZE=%s\n%w", s, err)
+		}
+		size = sz.Value
+	}
+
+	if count < 0 && size < 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("unable to read heap dump path %s\n%w", path, err)
+	}
+
+	var dumps, errorFiles []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		switch n := e.Name(); {
+		case strings.HasSuffix(n, ".hprof") || strings.HasSuffix(n, ".hprof.gz"):
+			dumps = append(dumps, fi)
+		case strings.Contains(n, "_hs_err_pid") && strings.HasSuffix(n, ".log"):
+			errorFiles = append(errorFiles, fi)
+		}
+	}
+
+	a.remove(path, "heap dump", dumps, count, size)
+	a.remove(path, "error file", errorFiles, count, size)
+
+	return nil
+}
+
+// remove removes the oldest files beyond count or the total size.
+func (a JVMHeapDump) remove(path string, kind string, files []os.FileInfo, count int, size int64) {
+	slices.SortFunc(files, func(a, b os.FileInfo) int {
+		return b.ModTime().Compare(a.ModTime())
+	})
+
+	var total int64
+	for i, f := range files {
+		total += f.Size()
+		if (count < 0 || i < count) && (size < 0 || total <= size) {
+			continue
+		}
+
+		file := filepath.Join(path, f.Name())
+		if err := os.Remove(file); err != nil {
+			a.Logger.Bodyf("WARNING: Unable to remove %s %s: %s", kind, file, err)
+			continue
+		}
+		a.Logger.Bodyf("Removed %s %s", kind, file)
+	}
 }
@@ -20,7 +20,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
@@ -106,5 +109,137 @@ func testJVMHeapDump(t *testing.T, context spec.G, it spec.S) {
 				}))
 			})
 		})
+
+		it("names heap dumps by host name, startup time and pid", func() {
+			host, err := os.Hostname()
+			Expect(err).NotTo(HaveOccurred())
+
+			env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(env["JAVA_TOOL_OPTIONS"]).To(MatchRegexp(`^-XX:\+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=%s/%s_[^ ]+_pid%%p\.hprof$`,
+				regexp.QuoteMeta(HeapDumpPath), regexp.QuoteMeta(host)))
+		})
+
+		context("$BPL_HEAP_DUMP_GZIP_LEVEL", func() {
+			it.Before(func() {
+				t.Setenv("BPL_HEAP_DUMP_GZIP_LEVEL", "1")
+			})
+
+			it("compresses heap dumps on Java 17 and later", func() {
+				t.Setenv("BPI_JVM_VERSION", "17.0.10")
+
+				env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(env["JAVA_TOOL_OPTIONS"]).To(MatchRegexp(`-XX:HeapDumpPath=\S+_pid%p\.hprof\.gz -XX:HeapDumpGzipLevel=1$`))
+			})
+
+			it("compresses heap dumps with a configured path", func() {
+				t.Setenv("BPI_JVM_VERSION", "17.0.10")
+				t.Setenv("JAVA_TOOL_OPTIONS", fmt.Sprintf("-XX:HeapDumpPath=%s", HeapDumpPath))
+
+				env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(env).To(Equal(map[string]string{
+					"JAVA_TOOL_OPTIONS": fmt.Sprintf("-XX:HeapDumpPath=%s -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpGzipLevel=1", HeapDumpPath),
+				}))
+			})
+
+			it("does not compress heap dumps before Java 17", func() {
+				t.Setenv("BPI_JVM_VERSION", "11.0.22")
+
+				env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(env["JAVA_TOOL_OPTIONS"]).To(HaveSuffix("_pid%p.hprof"))
+			})
+
+			it("returns error for an invalid level", func() {
+				t.Setenv("BPL_HEAP_DUMP_GZIP_LEVEL", "10")
+
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).To(MatchError("unable to parse $BPL_HEAP_DUMP_GZIP_LEVEL=10, expected 0 to 9"))
+			})
+		})
+
+		context("$BPL_HEAP_DUMP_CRASH_ON_OOM", func() {
+			it.Before(func() {
+				t.Setenv("BPL_HEAP_DUMP_CRASH_ON_OOM", "true")
+			})
+
+			it("crashes on OutOfMemoryError with the error file next to heap dumps", func() {
+				env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(env["JAVA_TOOL_OPTIONS"]).To(MatchRegexp(`-XX:\+CrashOnOutOfMemoryError -XX:ErrorFile=%s/\S+_hs_err_pid%%p\.log$`, regexp.QuoteMeta(HeapDumpPath)))
+			})
+
+			it("does not override a configured error file", func() {
+				t.Setenv("JAVA_TOOL_OPTIONS", "-XX:ErrorFile=/tmp/hs_err.log")
+
+				env, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(env["JAVA_TOOL_OPTIONS"]).To(HavePrefix("-XX:ErrorFile=/tmp/hs_err.log "))
+				Expect(env["JAVA_TOOL_OPTIONS"]).To(HaveSuffix(" -XX:+CrashOnOutOfMemoryError"))
+			})
+		})
+
+		context("retention", func() {
+			dump := func(name string, size int, age time.Duration) {
+				file := filepath.Join(HeapDumpPath, name)
+				Expect(os.WriteFile(file, make([]byte, size), 0644)).To(Succeed())
+				Expect(os.Chtimes(file, time.Now().Add(-age), time.Now().Add(-age))).To(Succeed())
+			}
+
+			it.Before(func() {
+				dump("a.hprof", 1024, 3*time.Hour)
+				dump("b.hprof.gz", 1024, 2*time.Hour)
+				dump("c.hprof", 1024, time.Hour)
+				dump("notes.txt", 1024, 4*time.Hour)
+				dump("a_hs_err_pid1.log", 512, 3*time.Hour)
+				dump("c_hs_err_pid1.log", 512, time.Hour)
+			})
+
+			it("does not remove heap dumps by default", func() {
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(HeapDumpPath, "a.hprof")).To(BeARegularFile())
+			})
+
+			it("removes the oldest heap dumps beyond $BPL_HEAP_DUMP_RETENTION_COUNT", func() {
+				t.Setenv("BPL_HEAP_DUMP_RETENTION_COUNT", "1")
+
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(HeapDumpPath, "a.hprof")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(HeapDumpPath, "b.hprof.gz")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(HeapDumpPath, "c.hprof")).To(BeARegularFile())
+				Expect(filepath.Join(HeapDumpPath, "notes.txt")).To(BeARegularFile())
+			})
+
+			it("removes the oldest error files beyond $BPL_HEAP_DUMP_RETENTION_COUNT", func() {
+				t.Setenv("BPL_HEAP_DUMP_RETENTION_COUNT", "1")
+
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(HeapDumpPath, "a_hs_err_pid1.log")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(HeapDumpPath, "c_hs_err_pid1.log")).To(BeARegularFile())
+				Expect(filepath.Join(HeapDumpPath, "c.hprof")).To(BeARegularFile())
+			})
+
+			it("removes the oldest heap dumps beyond $BPL_HEAP_DUMP_RETENTION_SIZE", func() {
+				t.Setenv("BPL_HEAP_DUMP_RETENTION_SIZE", "2K")
+
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(HeapDumpPath, "a.hprof")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(HeapDumpPath, "b.hprof.gz")).To(BeARegularFile())
+				Expect(filepath.Join(HeapDumpPath, "c.hprof")).To(BeARegularFile())
+			})
+
+			it("returns error for an invalid count", func() {
+				t.Setenv("BPL_HEAP_DUMP_RETENTION_COUNT", "-1")
+
+				_, err := helper.JVMHeapDump{Logger: log.NewPaketoLogger(io.Discard)}.Execute()
+				Expect(err).To(MatchError("unable to parse $BPL_HEAP_DUMP_RETENTION_COUNT=-1 as a non-negative integer"))
+			})
+		})
 	})
 }
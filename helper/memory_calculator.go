@@ -17,15 +17,21 @@
 package helper
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/mattn/go-shellwords"
 
 	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
 
 	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
 	"github.com/paketo-buildpacks/jvm-vendors/calc"
@@ -35,47 +41,98 @@ import (
 const (
 	ClassLoadFactor          = 0.35
 	DefaultHeadroom          = 0
+	DefaultCgroupPath        = "/proc/self/cgroup"
+	DefaultCgroupRoot        = "/sys/fs/cgroup"
 	DefaultMemoryLimitPathV1 = "/sys/fs/cgroup/memory/memory.limit_in_bytes"
-	DefaultMemoryLimitPathV2 = "/sys/fs/cgroup/memory.max"
 	DefaultMemoryInfoPath    = "/proc/meminfo"
+	DefaultReportPath        = "/tmp/jvm-memory-calculation.json"
 	MaxJVMSize               = 64 * calc.Tebi
 	UnsetTotalMemory         = int64(9_223_372_036_854_771_712)
 )
 
 type MemoryCalculator struct {
+	CgroupPath        string
+	CgroupRoot        string
 	Logger            log.Logger
 	MemoryLimitPathV1 string
-	MemoryLimitPathV2 string
 	MemoryInfoPath    string
+	ReportPath        string
+}
+
+// MemoryCalculationReport is the result of the memory calculation at launch, written to $BPL_JVM_MEMORY_REPORT_PATH so
+// that it can be read without parsing logs.
+type MemoryCalculationReport struct {
+	calc.Report
+	TotalMemorySource     string           `json:"total_memory_source"`
+	ThreadCountSource     string           `json:"thread_count_source,omitempty"`
+	DirectMemoryLibraries []string         `json:"direct_memory_libraries,omitempty"`
+	ClassCount            ClassCountInputs `json:"class_count"`
+}
+
+// ClassCountInputs are the inputs of the loaded class count. Only Configured is set when the count was configured with
+// $BPL_JVM_LOADED_CLASS_COUNT.
+type ClassCountInputs struct {
+	Configured        bool    `json:"configured"`
+	JVM               int     `json:"jvm,omitempty"`
+	Application       int     `json:"application,omitempty"`
+	ApplicationBuild  bool    `json:"application_counted_at_build,omitempty"`
+	Agents            int     `json:"agents,omitempty"`
+	StaticAdjustment  int     `json:"static_adjustment,omitempty"`
+	AdjustmentPercent uint64  `json:"adjustment_percent,omitempty"`
+	LoadFactor        float64 `json:"load_factor,omitempty"`
 }
 
 func (m MemoryCalculator) Execute() (map[string]string, error) {
 	var (
 		err error
 		c   = calc.Calculator{
-			HeadRoom:    DefaultHeadroom,
 			ThreadCount: calc.DefaultThreadCountValue,
 		}
 		deprecatedHeadroom bool
+		classCount         ClassCountInputs
+		threadCountSource  string
 	)
 
 	if s, ok := os.LookupEnv("BPL_JVM_HEADROOM"); ok {
-		if c.HeadRoom, err = strconv.Atoi(s); err != nil {
-			return nil, fmt.Errorf("unable to convert $BPL_JVM_HEADROOM=%s to integer\n%w", s, err)
+		if c.HeadRoom, err = calc.ParseHeadRoom(s); err != nil {
+			return nil, fmt.Errorf("unable to parse $BPL_JVM_HEADROOM=%s\n%w", s, err)
 		}
 		deprecatedHeadroom = true
 		m.Logger.Body("WARNING: BPL_JVM_HEADROOM is deprecated and will be removed, please switch to BPL_JVM_HEAD_ROOM")
 	}
 
 	if s, ok := os.LookupEnv("BPL_JVM_HEAD_ROOM"); ok {
-		if c.HeadRoom, err = strconv.Atoi(s); err != nil {
-			return nil, fmt.Errorf("unable to convert $BPL_JVM_HEAD_ROOM=%s to integer\n%w", s, err)
+		if c.HeadRoom, err = calc.ParseHeadRoom(s); err != nil {
+			return nil, fmt.Errorf("unable to parse $BPL_JVM_HEAD_ROOM=%s\n%w", s, err)
 		}
 		if deprecatedHeadroom {
 			m.Logger.Body("WARNING: You have set both BPL_JVM_HEAD_ROOM and BPL_JVM_HEADROOM. BPL_JVM_HEADROOM has been deprecated, so it will be ignored.")
 		}
 	}
 
+	var directMemoryLibraries []string
+	if s, ok := os.LookupEnv("BPL_JVM_DIRECT_MEMORY"); ok {
+		a, err := calc.ParseAmount(s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse $BPL_JVM_DIRECT_MEMORY=%s\n%w", s, err)
+		}
+		c.DirectMemory = &a
+	} else if appPath, ok := os.LookupEnv("BPI_APPLICATION_PATH"); ok {
+		if directMemoryLibraries, err = FindDirectMemoryLibraries(appPath); err != nil {
+			m.Logger.Bodyf("WARNING: Unable to find libraries that use direct memory: %s", err)
+		} else if len(directMemoryLibraries) > 0 {
+			c.DirectMemory = &DirectMemoryLibrariesAmount
+		}
+	}
+
+	if s, ok := os.LookupEnv("BPL_JVM_CODE_CACHE"); ok {
+		a, err := calc.ParseAmount(s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse $BPL_JVM_CODE_CACHE=%s\n%w", s, err)
+		}
+		c.ReservedCodeCache = &a
+	}
+
 	var values []string
 	opts, ok := os.LookupEnv("JAVA_TOOL_OPTIONS")
 	if ok {
@@ -86,6 +143,7 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 		if c.LoadedClassCount, err = strconv.Atoi(s); err != nil {
 			return nil, fmt.Errorf("unable to convert $BPL_JVM_LOADED_CLASS_COUNT=%s to integer\n%w", s, err)
 		}
+		classCount.Configured = true
 	} else {
 		appPath, ok := os.LookupEnv("BPI_APPLICATION_PATH")
 		if !ok {
@@ -120,15 +178,50 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 			}
 		}
 
-		appClassCount, err := count.Classes(appPath)
-
-		totalClasses := float64(jvmClassCount+appClassCount+agentClassCount+staticAdjustment) * (float64(adjustmentFactor) / 100.0)
-
+		appClassCount, fromBuild, err := m.applicationClassCount(appPath)
 		if err != nil {
 			return nil, fmt.Errorf("unable to determine class count\n%w", err)
 		}
+
+		totalClasses := float64(jvmClassCount+appClassCount+agentClassCount+staticAdjustment) * (float64(adjustmentFactor) / 100.0)
+
 		m.Logger.Debugf("Memory Calculation: (%d%% * (%d + %d + %d + %d)) * %0.2f", adjustmentFactor, jvmClassCount, appClassCount, agentClassCount, staticAdjustment, ClassLoadFactor)
 		c.LoadedClassCount = int(totalClasses * ClassLoadFactor)
+		classCount = ClassCountInputs{
+			JVM:               jvmClassCount,
+			Application:       appClassCount,
+			ApplicationBuild:  fromBuild,
+			Agents:            agentClassCount,
+			StaticAdjustment:  staticAdjustment,
+			AdjustmentPercent: adjustmentFactor,
+			LoadFactor:        ClassLoadFactor,
+		}
+	}
+
+	jvmVersion := os.Getenv("BPI_JVM_VERSION")
+	if v, err := semver.NewVersion(jvmVersion); err == nil {
+		c.JavaVersion = int(v.Major())
+	}
+
+	if s, ok := os.LookupEnv("BPL_JVM_CLASS_SIZE"); ok {
+		size, err := calc.ParseSize(s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse $BPL_JVM_CLASS_SIZE=%s\n%w", s, err)
+		}
+		c.ClassSize = size.Value
+	}
+
+	// the nmt helper runs after this one and is only contributed for Java 9 and later
+	if sherpa.ResolveBoolWithDefault("BPL_JAVA_NMT_ENABLED", true) && !jvmvendors.IsBeforeJava9(jvmVersion) {
+		c.NativeMemoryTracking = sherpa.GetEnvWithDefault("BPL_JAVA_NMT_LEVEL", calc.NMTSummary)
+	}
+
+	c.MallocArenas = calc.DefaultMallocArenas
+	if s, ok := os.LookupEnv("MALLOC_ARENA_MAX"); ok {
+		var err error
+		if c.MallocArenas, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("unable to convert $MALLOC_ARENA_MAX=%s to integer\n%w", s, err)
+		}
 	}
 
 	if threadCount, ok := os.LookupEnv("BPL_JVM_THREAD_COUNT"); ok {
@@ -137,31 +230,34 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 			return nil, fmt.Errorf("unable to convert $BPL_JVM_THREAD_COUNT=%s to integer\n%w", threadCount, err)
 		}
 		c.ThreadCount = calc.ThreadCount{Value: v, Provenance: calc.UserConfigured}
-	}
+		threadCountSource = "$BPL_JVM_THREAD_COUNT"
+	} else if appPath, ok := os.LookupEnv("BPI_APPLICATION_PATH"); ok {
+		// the processors available to the container rather than the host
+		processors, err := ActiveProcessorCount{CgroupPath: m.CgroupPath, CgroupRoot: m.CgroupRoot, Logger: m.Logger}.Count()
+		if err != nil {
+			m.Logger.Bodyf("WARNING: Unable to determine active processor count, using %d: %s", runtime.NumCPU(), err)
+			processors = runtime.NumCPU()
+		}
 
-	totalMemory := m.getMemoryLimitFromPath(m.MemoryLimitPathV1)
-	if totalMemory == UnsetTotalMemory {
-		totalMemory = m.getMemoryLimitFromPath(m.MemoryLimitPathV2)
+		if t, ok, err := InferThreadCount(appPath, c.JavaVersion, processors); err != nil {
+			m.Logger.Bodyf("WARNING: Unable to infer thread count from application configuration: %s", err)
+		} else if ok {
+			c.ThreadCount = t.ThreadCount
+			threadCountSource = fmt.Sprintf("%s in %s", t.Property, t.Source)
+			m.Logger.Bodyf("Inferred thread count of %d from %s", t.Value, threadCountSource)
+		}
 	}
 
-	if totalMemory == UnsetTotalMemory {
-		if b, err := os.ReadFile(m.MemoryInfoPath); err != nil && !os.IsNotExist(err) {
-			m.Logger.Bodyf(`WARNING: failed to read %q: %s`, m.MemoryInfoPath, err)
-		} else if err == nil {
-			if mem, err := parseMemInfo(string(b)); err != nil {
-				m.Logger.Bodyf(`WARNING: failed to parse available memory from path %q: %s`, m.MemoryInfoPath, err)
-			} else {
-				m.Logger.Bodyf("Calculating JVM memory based on %s available memory", calc.Size{Value: mem}.String())
-				m.Logger.Body("For more information on this calculation, see https://paketo.io/docs/reference/java-reference/#memory-calculator")
-				totalMemory = mem
-			}
-		}
+	totalMemory, totalMemorySource, err := m.getTotalMemory()
+	if err != nil {
+		return nil, err
 	}
 
 	switch {
 	case totalMemory == UnsetTotalMemory:
 		m.Logger.Body("WARNING: Unable to determine memory limit. Configuring JVM for 1G container.")
 		c.TotalMemory = calc.Size{Value: calc.Gibi}
+		totalMemorySource = "default"
 	case totalMemory > MaxJVMSize:
 		m.Logger.Body("WARNING: Container memory limit too large. Configuring JVM for 64T container.")
 		c.TotalMemory = calc.Size{Value: MaxJVMSize}
@@ -170,11 +266,7 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 	}
 
 	if c.TotalMemory.Value < calc.LowProfileThreshold {
-		m.Logger.Bodyf("WARNING: Container memory is below %s. A future release will automatically "+
-			"apply a low memory profile, which adjusts JVM memory settings for small containers. "+
-			"To opt out and retain the existing JVM memory settings, set BPL_LOW_MEMORY_PROFILE_DISABLED=true.",
-			calc.Size{Value: calc.LowProfileThreshold})
-
+		c.LowProfile = true
 		if s, ok := os.LookupEnv("BPL_LOW_MEMORY_PROFILE_DISABLED"); ok {
 			if v, err := strconv.ParseBool(s); err != nil {
 				return nil, fmt.Errorf("unable to convert $BPL_LOW_MEMORY_PROFILE_DISABLED=%s to boolean\n%w", s, err)
@@ -182,6 +274,11 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 				c.LowProfile = !v
 			}
 		}
+
+		if !c.LowProfile {
+			m.Logger.Bodyf("WARNING: Container memory is below %s and the low memory profile is disabled. "+
+				"The default JVM memory settings may not fit the container.", calc.Size{Value: calc.LowProfileThreshold})
+		}
 	}
 
 	o, err := c.Calculate(opts)
@@ -196,42 +293,170 @@ func (m MemoryCalculator) Execute() (map[string]string, error) {
 			"It is strongly recommended to let the calculator manage heap size.")
 	}
 
-	if mem.ReservedCodeCache.Provenance == calc.Calculated && mem.ReservedCodeCache.Value < 240*calc.Mebi {
+	if mem.ReservedCodeCache.Provenance == calc.Calculated && c.ReservedCodeCache == nil && mem.ReservedCodeCache.Value < 240*calc.Mebi {
 		m.Logger.Bodyf("WARNING: Code cache size is %s, below the default of 240M. "+
 			"JIT compilation performance may be reduced, especially under load.", mem.ReservedCodeCache)
 	}
 
-	var calculated []string
-	if mem.DirectMemory.Provenance != calc.UserConfigured {
-		calculated = append(calculated, mem.DirectMemory.String())
+	// a -XX:MaxDirectMemorySize in $JAVA_TOOL_OPTIONS takes precedence over the libraries
+	if len(directMemoryLibraries) > 0 && mem.DirectMemory.Provenance == calc.Calculated {
+		m.Logger.Bodyf("Increased direct memory to %s, %s of total memory, as the application uses %s, which allocate I/O buffers in direct memory. Set $BPL_JVM_DIRECT_MEMORY to configure it.",
+			calc.Size(mem.DirectMemory), DirectMemoryLibrariesAmount, strings.Join(directMemoryLibraries, ", "))
+	} else {
+		directMemoryLibraries = nil
 	}
-	if mem.Heap.Provenance != calc.UserConfigured {
-		calculated = append(calculated, mem.Heap.String())
+
+	calculated := mem.CalculatedFlags()
+	values = append(values, calculated...)
+
+	if c.LowProfile {
+		values = append(values, o.LowProfileFlags...)
+		m.Logger.Bodyf("Applying low memory profile for container below %s: scaling factor %.2f, %s",
+			calc.Size{Value: calc.LowProfileThreshold}, mem.ScalingFactor, strings.Join(o.LowProfileFlags, " "))
 	}
-	if mem.Metaspace.Provenance != calc.UserConfigured {
-		calculated = append(calculated, mem.Metaspace.String())
+
+	m.Logger.Debugf("Memory Calculation: %s", mem.NativeOverhead)
+	m.Logger.Bodyf("Calculated JVM Memory Configuration: %s (Total Memory: %s, Thread Count: %d, Loaded Class Count: %d, Headroom: %s)",
+		strings.Join(calculated, " "), c.TotalMemory, o.ThreadCount.Value, c.LoadedClassCount, c.HeadRoom)
+
+	m.writeReport(MemoryCalculationReport{
+		Report:                calc.NewReport(c, o),
+		TotalMemorySource:     totalMemorySource,
+		ThreadCountSource:     threadCountSource,
+		DirectMemoryLibraries: directMemoryLibraries,
+		ClassCount:            classCount,
+	})
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+// writeReport writes the report to $BPL_JVM_MEMORY_REPORT_PATH, or the report path if it is not set. A report that
+// cannot be written does not prevent the JVM from starting.
+func (m MemoryCalculator) writeReport(r MemoryCalculationReport) {
+	path := sherpa.GetEnvWithDefault("BPL_JVM_MEMORY_REPORT_PATH", m.ReportPath)
+	if path == "" {
+		return
 	}
-	if mem.ReservedCodeCache.Provenance != calc.UserConfigured {
-		calculated = append(calculated, mem.ReservedCodeCache.String())
+
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		m.Logger.Bodyf("WARNING: Unable to encode memory calculation report: %s", err)
+		return
 	}
-	if mem.Stack.Provenance != calc.UserConfigured {
-		calculated = append(calculated, mem.Stack.String())
+
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		m.Logger.Bodyf("WARNING: Unable to write memory calculation report to %s: %s", path, err)
+		return
 	}
-	values = append(values, calculated...)
+	m.Logger.Debugf("Memory Calculation: report written to %s", path)
+}
 
-	if c.LowProfile {
-		if jvmVersion, ok := os.LookupEnv("BPI_JVM_VERSION"); ok && jvmvendors.IsJava25OrLater(jvmVersion) {
-			if !strings.Contains(opts, "-XX:+UseCompactObjectHeaders") {
-				values = append(values, "-XX:+UseCompactObjectHeaders")
-				m.Logger.Body("Enabling compact object headers for low-memory Java 25+ container")
-			}
+// applicationClassCount returns the application class count from $BPI_APP_CLASS_COUNT, and true, if the application has
+// not changed since it was counted at build time. Otherwise, the application's classes are counted.
+func (m MemoryCalculator) applicationClassCount(path string) (int, bool, error) {
+	if s, ok := os.LookupEnv("BPI_APP_CLASS_COUNT"); ok {
+		c, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, false, fmt.Errorf("unable to convert $BPI_APP_CLASS_COUNT=%s to integer\n%w", s, err)
+		}
+
+		fingerprint, err := count.Fingerprint(path)
+		if err != nil {
+			return 0, false, fmt.Errorf("unable to fingerprint application\n%w", err)
+		}
+
+		if fingerprint == os.Getenv("BPI_APP_CLASS_COUNT_FINGERPRINT") {
+			return c, true, nil
 		}
+		m.Logger.Debug("Memory Calculation: application has changed since it was built, counting classes")
 	}
 
-	m.Logger.Bodyf("Calculated JVM Memory Configuration: %s (Total Memory: %s, Thread Count: %d, Loaded Class Count: %d, Headroom: %d%%)",
-		strings.Join(calculated, " "), c.TotalMemory, o.ThreadCount.Value, c.LoadedClassCount, c.HeadRoom)
+	c, err := count.Classes(path)
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to count classes in %s\n%w", path, err)
+	}
+	return c, false, nil
+}
 
-	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+// getTotalMemory returns the memory available to the JVM and its source. $BPL_JVM_TOTAL_MEMORY takes precedence over
+// the memory limit. If $BPL_JVM_MEMORY_REQUEST_FILE names a file with a smaller value, such as a pod's memory request
+// from the Kubernetes downward API, it is used instead of the limit.
+func (m MemoryCalculator) getTotalMemory() (int64, string, error) {
+	if s, ok := os.LookupEnv("BPL_JVM_TOTAL_MEMORY"); ok {
+		size, err := calc.ParseSize(s)
+		if err != nil {
+			return 0, "", fmt.Errorf("unable to parse $BPL_JVM_TOTAL_MEMORY=%s\n%w", s, err)
+		}
+		m.Logger.Bodyf("Using total memory of %s from $BPL_JVM_TOTAL_MEMORY", size)
+		return size.Value, "$BPL_JVM_TOTAL_MEMORY", nil
+	}
+
+	limit, source := m.getMemoryLimit()
+
+	if path, ok := os.LookupEnv("BPL_JVM_MEMORY_REQUEST_FILE"); ok {
+		if request, ok := m.getMemoryFromFile(path); ok && request > 0 && request < limit {
+			m.Logger.Bodyf("Using memory request of %s from %s", calc.Size{Value: request}, path)
+			return request, path, nil
+		}
+	}
+
+	return limit, source, nil
+}
+
+// getMemoryLimit returns the memory limit from the file named by $BPL_JVM_MEMORY_LIMIT_FILE, the cgroup v1 or v2
+// limits, or the available memory of the host, in that order, and its source.
+func (m MemoryCalculator) getMemoryLimit() (int64, string) {
+	if path, ok := os.LookupEnv("BPL_JVM_MEMORY_LIMIT_FILE"); ok {
+		if limit, ok := m.getMemoryFromFile(path); ok {
+			m.Logger.Bodyf("Using memory limit of %s from %s", calc.Size{Value: limit}, path)
+			return limit, path
+		}
+	}
+
+	limit, limitPath := m.getMemoryLimitFromPath(m.MemoryLimitPathV1), m.MemoryLimitPathV1
+	if limit == UnsetTotalMemory {
+		limit, limitPath = m.getCgroupV2MemoryLimit()
+	}
+	if limit != UnsetTotalMemory {
+		m.Logger.Bodyf("Using memory limit of %s from %s", calc.Size{Value: limit}, limitPath)
+		return limit, limitPath
+	}
+
+	if b, err := os.ReadFile(m.MemoryInfoPath); err != nil && !os.IsNotExist(err) {
+		m.Logger.Bodyf(`WARNING: failed to read %q: %s`, m.MemoryInfoPath, err)
+	} else if err == nil {
+		if mem, err := parseMemInfo(string(b)); err != nil {
+			m.Logger.Bodyf(`WARNING: failed to parse available memory from path %q: %s`, m.MemoryInfoPath, err)
+		} else {
+			m.Logger.Bodyf("Calculating JVM memory based on %s available memory", calc.Size{Value: mem}.String())
+			m.Logger.Body("For more information on this calculation, see https://paketo.io/docs/reference/java-reference/#memory-calculator")
+			return mem, m.MemoryInfoPath
+		}
+	}
+
+	return UnsetTotalMemory, ""
+}
+
+// getMemoryFromFile reads a memory size from a file, such as a Kubernetes downward API file. Sizes may have a binary
+// suffix, as Kubernetes quantities do.
+func (m MemoryCalculator) getMemoryFromFile(path string) (int64, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		m.Logger.Bodyf("WARNING: Unable to read %s: %s", path, err)
+		return 0, false
+	}
+
+	s := strings.TrimSpace(string(b))
+	if t, ok := strings.CutSuffix(s, "i"); ok && strings.ContainsAny(t[len(t)-min(len(t), 1):], "KMGT") {
+		s = t
+	}
+
+	size, err := calc.ParseSize(s)
+	if err != nil {
+		m.Logger.Bodyf("WARNING: Unable to parse memory size %q from %s: %s", s, path, err)
+		return 0, false
+	}
+	return size.Value, true
 }
 
 func (m MemoryCalculator) getMemoryLimitFromPath(memoryLimitPath string) int64 {
@@ -251,6 +476,77 @@ func (m MemoryCalculator) getMemoryLimitFromPath(memoryLimitPath string) int64 {
 	return UnsetTotalMemory
 }
 
+// getCgroupV2MemoryLimit returns the tightest memory.max or memory.high of the process' cgroup and its ancestors,
+// and the file that set it.
+func (m MemoryCalculator) getCgroupV2MemoryLimit() (int64, string) {
+	root := filepath.Clean(m.CgroupRoot)
+
+	// the cgroup is not visible below the root when the container has its own cgroup namespace, or when its cgroup is
+	// mounted as the root
+	dir := root
+	if p, ok := m.getCgroupV2Path(); ok {
+		if fi, err := os.Stat(filepath.Join(root, p)); err == nil && fi.IsDir() {
+			dir = filepath.Join(root, p)
+		}
+	}
+
+	limit, limitPath := UnsetTotalMemory, ""
+	swap, swapPath := UnsetTotalMemory, ""
+	for {
+		for _, f := range []string{"memory.max", "memory.high"} {
+			if l := m.getMemoryLimitFromPath(filepath.Join(dir, f)); l < limit {
+				limit, limitPath = l, filepath.Join(dir, f)
+			}
+		}
+
+		if l := m.getMemoryLimitFromPath(filepath.Join(dir, "memory.swap.max")); l < swap {
+			swap, swapPath = l, filepath.Join(dir, "memory.swap.max")
+		}
+
+		if dir == root || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	if limit != UnsetTotalMemory && swap != UnsetTotalMemory && swap > 0 {
+		m.Logger.Bodyf("WARNING: %s allows %s of swap, which is not included in the memory available to the JVM",
+			swapPath, calc.Size{Value: swap})
+	}
+
+	return limit, limitPath
+}
+
+// getCgroupV2Path returns the process' cgroup v2 path, warning if it cannot be read.
+func (m MemoryCalculator) getCgroupV2Path() (string, bool) {
+	p, ok, err := readCgroupV2Path(m.CgroupPath)
+	if err != nil {
+		m.Logger.Bodyf("WARNING: Unable to read %s: %s", m.CgroupPath, err)
+	}
+	return p, ok
+}
+
+// readCgroupV2Path returns the process' cgroup v2 path from the unified hierarchy entry, 0::<path>, of cgroupPath,
+// which is usually /proc/self/cgroup.
+func readCgroupV2Path(cgroupPath string) (string, bool, error) {
+	f, err := os.Open(cgroupPath)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return p, true, nil
+		}
+	}
+
+	return "", false, scanner.Err()
+}
+
 func parseMemInfo(s string) (int64, error) {
 	pattern := `MemAvailable:\s*(\d+)(.*)`
 	rp := regexp.MustCompile(pattern)
@@ -277,8 +573,9 @@ func (m MemoryCalculator) CountAgentClasses(opts string) (int, error) {
 	} else {
 		var agentPaths []string
 		for _, s := range p {
-			if strings.HasPrefix(s, "-javaagent:") {
-				agentPaths = append(agentPaths, strings.Split(s, ":")[1])
+			if path, ok := strings.CutPrefix(s, "-javaagent:"); ok {
+				path, _, _ = strings.Cut(path, "=")
+				agentPaths = append(agentPaths, path)
 			}
 		}
 		if len(agentPaths) > 0 {
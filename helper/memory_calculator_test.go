@@ -17,10 +17,12 @@
 package helper_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -31,6 +33,7 @@ import (
 	"github.com/paketo-buildpacks/libpak/v2/log"
 
 	"github.com/paketo-buildpacks/jvm-vendors/calc"
+	"github.com/paketo-buildpacks/jvm-vendors/count"
 	"github.com/paketo-buildpacks/jvm-vendors/helper"
 )
 
@@ -39,9 +42,11 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 		Expect = NewWithT(t).Expect
 
 		applicationPath   string
+		cgroupPath        string
+		cgroupRoot        string
 		memoryLimitPathV1 string
-		memoryLimitPathV2 string
 		memoryInfoPath    string
+		reportPath        string
 		m                 helper.MemoryCalculator
 	)
 
@@ -49,6 +54,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 		var err error
 
 		applicationPath = t.TempDir()
+		t.Setenv("MALLOC_ARENA_MAX", "2")
 
 		limitV1, err := os.CreateTemp("", "memory-calculator-memory-limit-v1")
 		Expect(err).NotTo(HaveOccurred())
@@ -56,11 +62,8 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 		Expect(os.RemoveAll(limitV1.Name())).To(Succeed())
 		memoryLimitPathV1 = limitV1.Name()
 
-		limitV2, err := os.CreateTemp("", "memory-calculator-memory-limit-v2")
-		Expect(err).NotTo(HaveOccurred())
-		Expect(limitV2.Close()).To(Succeed())
-		Expect(os.RemoveAll(limitV2.Name())).To(Succeed())
-		memoryLimitPathV2 = limitV2.Name()
+		cgroupPath = filepath.Join(t.TempDir(), "cgroup")
+		cgroupRoot = t.TempDir()
 
 		info, err := os.CreateTemp("", "memory-calculator-memory-info")
 		Expect(err).NotTo(HaveOccurred())
@@ -68,10 +71,14 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 		Expect(os.RemoveAll(info.Name())).To(Succeed())
 		memoryInfoPath = info.Name()
 
+		reportPath = filepath.Join(t.TempDir(), "jvm-memory-calculation.json")
+
 		m = helper.MemoryCalculator{
+			CgroupPath:        cgroupPath,
+			CgroupRoot:        cgroupRoot,
 			MemoryLimitPathV1: memoryLimitPathV1,
-			MemoryLimitPathV2: memoryLimitPathV2,
 			MemoryInfoPath:    memoryInfoPath,
+			ReportPath:        reportPath,
 			Logger:            log.NewPaketoLogger(io.Discard),
 		}
 	})
@@ -79,7 +86,6 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 	it.After(func() {
 		Expect(os.RemoveAll(applicationPath)).To(Succeed())
 		Expect(os.RemoveAll(memoryLimitPathV1)).To(Succeed())
-		Expect(os.RemoveAll(memoryLimitPathV2)).To(Succeed())
 	})
 
 	it("returns error if $BPI_APPLICATION_PATH is not set", func() {
@@ -106,7 +112,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 			it("returns default options", func() {
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
@@ -119,7 +125,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 						it("adjusts by a static factor", func() {
 							Expect(m.Execute()).To(Equal(map[string]string{
-								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522688K -XX:MaxMetaspaceSize=13887K -XX:ReservedCodeCacheSize=240M -Xss1M",
+								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497811K -XX:MaxMetaspaceSize=13887K -XX:ReservedCodeCacheSize=240M -Xss1M",
 							}))
 						})
 					})
@@ -131,7 +137,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 						it("adjusts by a static factor", func() {
 							Expect(m.Execute()).To(Equal(map[string]string{
-								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522785K -XX:MaxMetaspaceSize=13790K -XX:ReservedCodeCacheSize=240M -Xss1M",
+								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497932K -XX:MaxMetaspaceSize=13790K -XX:ReservedCodeCacheSize=240M -Xss1M",
 							}))
 						})
 					})
@@ -145,7 +151,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 						it("adjusts by a percentage", func() {
 							Expect(m.Execute()).To(Equal(map[string]string{
-								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522688K -XX:MaxMetaspaceSize=13887K -XX:ReservedCodeCacheSize=240M -Xss1M",
+								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497811K -XX:MaxMetaspaceSize=13887K -XX:ReservedCodeCacheSize=240M -Xss1M",
 							}))
 						})
 					})
@@ -157,7 +163,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 						it("adjusts by a percentage", func() {
 							Expect(m.Execute()).To(Equal(map[string]string{
-								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522785K -XX:MaxMetaspaceSize=13790K -XX:ReservedCodeCacheSize=240M -Xss1M",
+								"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497932K -XX:MaxMetaspaceSize=13790K -XX:ReservedCodeCacheSize=240M -Xss1M",
 							}))
 						})
 					})
@@ -183,7 +189,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("passes $BPL_JVM_HEADROOM to calculator", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx417848K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx392975K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
 			})
@@ -195,7 +201,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("passes $BPL_JVM_HEAD_ROOM to calculator", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx417848K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx392975K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
 			})
@@ -208,8 +214,110 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("passes $BPL_JVM_HEAD_ROOM to calculator", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx417848K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx392975K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+			})
+
+			context("$BPL_JVM_HEAD_ROOM as an amount", func() {
+				it("passes an absolute size to calculator", func() {
+					t.Setenv("BPL_JVM_HEAD_ROOM", "256M")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx235688K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("passes the largest of a percentage and a size to calculator", func() {
+					t.Setenv("BPL_JVM_HEAD_ROOM", "max(5%, 128M)")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx366760K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("returns error if $BPL_JVM_HEAD_ROOM is not an amount", func() {
+					t.Setenv("BPL_JVM_HEAD_ROOM", "lots")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_HEAD_ROOM=lots")))
+				})
+			})
+
+			context("$BPL_JVM_DIRECT_MEMORY and $BPL_JVM_CODE_CACHE", func() {
+				it("sizes direct memory and reserved code cache from total memory", func() {
+					t.Setenv("BPL_JVM_DIRECT_MEMORY", "5%")
+					t.Setenv("BPL_JVM_CODE_CACHE", "min(10%, 128M)")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=52428K -Xmx596546K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=104857K -Xss1M",
+					}))
+				})
+
+				it("does not override sizes in $JAVA_TOOL_OPTIONS", func() {
+					t.Setenv("BPL_JVM_DIRECT_MEMORY", "5%")
+					t.Setenv("JAVA_TOOL_OPTIONS", "-XX:MaxDirectMemorySize=64M")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=64M -Xmx442536K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("returns error if $BPL_JVM_DIRECT_MEMORY is not an amount", func() {
+					t.Setenv("BPL_JVM_DIRECT_MEMORY", "110%")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_DIRECT_MEMORY=110%")))
+				})
+
+				it("returns error if $BPL_JVM_CODE_CACHE is not an amount", func() {
+					t.Setenv("BPL_JVM_CODE_CACHE", "big")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_CODE_CACHE=big")))
+				})
+			})
+
+			context("libraries that use direct memory", func() {
+				var logOutput strings.Builder
+
+				it.Before(func() {
+					logOutput.Reset()
+					m.Logger = log.NewPaketoLogger(&logOutput)
+
+					Expect(os.MkdirAll(filepath.Join(applicationPath, "BOOT-INF", "lib"), 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(applicationPath, "BOOT-INF", "lib", "netty-buffer-4.1.100.Final.jar"), []byte{}, 0644)).To(Succeed())
+				})
+
+				it("increases direct memory", func() {
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=104857K -Xmx403215K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
+					Expect(logOutput.String()).To(ContainSubstring("Increased direct memory to 104857K, max(10%, 32M) of total memory, as the application uses netty-buffer"))
+
+					b, err := os.ReadFile(reportPath)
+					Expect(err).NotTo(HaveOccurred())
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+					Expect(r.DirectMemoryLibraries).To(Equal([]string{"netty-buffer"}))
+				})
+
+				it("prefers $BPL_JVM_DIRECT_MEMORY", func() {
+					t.Setenv("BPL_JVM_DIRECT_MEMORY", "10M")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).NotTo(ContainSubstring("Increased direct memory"))
+				})
+
+				it("prefers -XX:MaxDirectMemorySize in $JAVA_TOOL_OPTIONS", func() {
+					t.Setenv("JAVA_TOOL_OPTIONS", "-XX:MaxDirectMemorySize=10M")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).NotTo(ContainSubstring("Increased direct memory"))
 				})
 			})
 
@@ -220,9 +328,110 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("passes $BPL_JVM_LOADED_CLASS_COUNT to calculator", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522337K -XX:MaxMetaspaceSize=14238K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497369K -XX:MaxMetaspaceSize=14238K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+			})
+
+			context("$BPI_APP_CLASS_COUNT", func() {
+				var classCount = func() helper.ClassCountInputs {
+					b, err := os.ReadFile(reportPath)
+					Expect(err).NotTo(HaveOccurred())
+
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+					return r.ClassCount
+				}
+
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(applicationPath, "alpha.class"), []byte{}, 0600)).To(Succeed())
+					t.Setenv("BPI_APP_CLASS_COUNT", "1000")
+				})
+
+				it("uses the build time count if the application has not changed", func() {
+					fingerprint, err := count.Fingerprint(applicationPath)
+					Expect(err).NotTo(HaveOccurred())
+					t.Setenv("BPI_APP_CLASS_COUNT_FINGERPRINT", fingerprint)
+
+					_, err = m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(classCount().Application).To(Equal(1000))
+					Expect(classCount().ApplicationBuild).To(BeTrue())
+				})
+
+				it("counts classes if the application has changed", func() {
+					t.Setenv("BPI_APP_CLASS_COUNT_FINGERPRINT", "stale")
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(classCount().Application).To(Equal(1))
+					Expect(classCount().ApplicationBuild).To(BeFalse())
+				})
+
+				it("returns error if $BPI_APP_CLASS_COUNT is not an integer", func() {
+					t.Setenv("BPI_APP_CLASS_COUNT", "x")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(ContainSubstring("unable to convert $BPI_APP_CLASS_COUNT=x to integer")))
+				})
+			})
+
+			context("$BPL_JVM_CLASS_SIZE", func() {
+				it("overrides the metaspace per class", func() {
+					t.Setenv("BPL_JVM_CLASS_SIZE", "10K")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497681K -XX:MaxMetaspaceSize=14021K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("returns error if $BPL_JVM_CLASS_SIZE is not a size", func() {
+					t.Setenv("BPL_JVM_CLASS_SIZE", "large")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(ContainSubstring("unable to parse $BPL_JVM_CLASS_SIZE=large")))
+				})
+			})
+
+			context("native overhead", func() {
+				it("excludes Native Memory Tracking when disabled", func() {
+					t.Setenv("BPL_JAVA_NMT_ENABLED", "false")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx499880K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("excludes Native Memory Tracking before Java 9", func() {
+					t.Setenv("BPI_JVM_VERSION", "8.0.452")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx501813K -XX:MaxMetaspaceSize=11937K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("uses $MALLOC_ARENA_MAX", func() {
+					t.Setenv("MALLOC_ARENA_MAX", "4")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx489640K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("defaults malloc arenas to the value contributed by the JRE layer", func() {
+					Expect(os.Unsetenv("MALLOC_ARENA_MAX")).To(Succeed())
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
+
+				it("returns error if $MALLOC_ARENA_MAX is not an integer", func() {
+					t.Setenv("MALLOC_ARENA_MAX", "two")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(HavePrefix("unable to convert $MALLOC_ARENA_MAX=two to integer")))
+				})
 			})
 
 			context("$BPL_JVM_THREAD_COUNT", func() {
@@ -232,8 +441,79 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("passes $BPL_JVM_THREAD_COUNT to calculator", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx676305K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx651432K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+			})
+
+			context("thread count inferred from the application", func() {
+				var logOutput strings.Builder
+
+				it.Before(func() {
+					logOutput.Reset()
+					m.Logger = log.NewPaketoLogger(&logOutput)
+
+					Expect(os.MkdirAll(filepath.Join(applicationPath, "BOOT-INF", "classes"), 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(applicationPath, "BOOT-INF", "classes", "application.properties"),
+						[]byte("server.tomcat.threads.max=50\n"), 0644)).To(Succeed())
+				})
+
+				it("passes the inferred thread count to calculator", func() {
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx651432K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring("Inferred thread count of 100 from server.tomcat.threads.max in "))
+
+					b, err := os.ReadFile(reportPath)
+					Expect(err).NotTo(HaveOccurred())
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+					Expect(r.ThreadCountProvenance).To(Equal(calc.Calculated))
+					Expect(r.ThreadCountSource).To(HavePrefix("server.tomcat.threads.max in "))
+				})
+
+				it("infers virtual thread carriers from the active processor count", func() {
+					t.Setenv("BPI_JVM_VERSION", "21.0.2")
+					t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "4")
+					Expect(os.WriteFile(filepath.Join(applicationPath, "BOOT-INF", "classes", "application.properties"),
+						[]byte("spring.threads.virtual.enabled=true\n"), 0644)).To(Succeed())
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).To(ContainSubstring(fmt.Sprintf("Inferred thread count of %d from spring.threads.virtual.enabled in ",
+						helper.NonRequestThreadCount+4)))
+				})
+
+				it("falls back to the host processor count if the active processor count is invalid", func() {
+					t.Setenv("BPI_JVM_VERSION", "21.0.2")
+					t.Setenv("BPL_JVM_ACTIVE_PROCESSOR_COUNT", "lots")
+					Expect(os.WriteFile(filepath.Join(applicationPath, "BOOT-INF", "classes", "application.properties"),
+						[]byte("spring.threads.virtual.enabled=true\n"), 0644)).To(Succeed())
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).To(ContainSubstring("WARNING: Unable to determine active processor count"))
+					Expect(logOutput.String()).To(ContainSubstring(fmt.Sprintf("Inferred thread count of %d from spring.threads.virtual.enabled in ",
+						helper.NonRequestThreadCount+runtime.NumCPU())))
+				})
+
+				it("prefers $BPL_JVM_THREAD_COUNT", func() {
+					t.Setenv("BPL_JVM_THREAD_COUNT", "250")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).NotTo(ContainSubstring("Inferred thread count"))
+				})
+
+				it("warns if the configuration is invalid", func() {
+					Expect(os.WriteFile(filepath.Join(applicationPath, "BOOT-INF", "classes", "application.properties"),
+						[]byte("server.tomcat.threads.max=lots\n"), 0644)).To(Succeed())
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
+					Expect(logOutput.String()).To(ContainSubstring("WARNING: Unable to infer thread count from application configuration"))
 				})
 			})
 
@@ -249,7 +529,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 				Expect(os.WriteFile(memoryInfoPath, []byte(s), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx10988265K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx10052747K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
@@ -265,7 +545,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 				Expect(os.WriteFile(memoryInfoPath, []byte(s), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
@@ -273,7 +553,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 				Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, helper.UnsetTotalMemory, 10), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
@@ -281,7 +561,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 				Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, helper.MaxJVMSize+1, 10), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx68718950865K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx63221378339K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
@@ -289,47 +569,154 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 				Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 10*calc.Gibi, 10), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9959889K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9106641K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
+			context("total memory overrides", func() {
+				var (
+					dir       string
+					logOutput strings.Builder
+				)
+
+				it.Before(func() {
+					dir = t.TempDir()
+					logOutput.Reset()
+					m.Logger = log.NewPaketoLogger(&logOutput)
+					Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 20*calc.Gibi, 10), 0600)).To(Succeed())
+				})
+
+				it("uses $BPL_JVM_TOTAL_MEMORY over the container limit", func() {
+					t.Setenv("BPL_JVM_TOTAL_MEMORY", "10G")
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9106641K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring("Using total memory of 10G from $BPL_JVM_TOTAL_MEMORY"))
+				})
+
+				it("returns error if $BPL_JVM_TOTAL_MEMORY is not a size", func() {
+					t.Setenv("BPL_JVM_TOTAL_MEMORY", "ten gigabytes")
+
+					_, err := m.Execute()
+					Expect(err).To(MatchError(HavePrefix("unable to parse $BPL_JVM_TOTAL_MEMORY=ten gigabytes")))
+				})
+
+				it("uses the limit in $BPL_JVM_MEMORY_LIMIT_FILE", func() {
+					path := filepath.Join(dir, "limit")
+					Expect(os.WriteFile(path, []byte(strconv.FormatInt(10*calc.Gibi, 10)+"\n"), 0600)).To(Succeed())
+					t.Setenv("BPL_JVM_MEMORY_LIMIT_FILE", path)
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9106641K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("accepts Kubernetes quantities in $BPL_JVM_MEMORY_LIMIT_FILE", func() {
+					path := filepath.Join(dir, "limit")
+					Expect(os.WriteFile(path, []byte("10Gi"), 0600)).To(Succeed())
+					t.Setenv("BPL_JVM_MEMORY_LIMIT_FILE", path)
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9106641K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+
+				it("warns and falls back to the container limit if $BPL_JVM_MEMORY_LIMIT_FILE does not exist", func() {
+					path := filepath.Join(dir, "limit")
+					t.Setenv("BPL_JVM_MEMORY_LIMIT_FILE", path)
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).To(ContainSubstring("WARNING: Unable to read " + path))
+					Expect(logOutput.String()).To(ContainSubstring("Using memory limit of 20G from " + memoryLimitPathV1))
+				})
+
+				it("uses a memory request smaller than the limit", func() {
+					path := filepath.Join(dir, "request")
+					Expect(os.WriteFile(path, []byte(strconv.FormatInt(10*calc.Gibi, 10)), 0600)).To(Succeed())
+					t.Setenv("BPL_JVM_MEMORY_REQUEST_FILE", path)
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx9106641K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring("Using memory request of 10G from " + path))
+
+					b, err := os.ReadFile(reportPath)
+					Expect(err).NotTo(HaveOccurred())
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+					Expect(r.TotalMemorySource).To(Equal(path))
+				})
+
+				it("ignores an unset memory request", func() {
+					path := filepath.Join(dir, "request")
+					Expect(os.WriteFile(path, []byte("0"), 0600)).To(Succeed())
+					t.Setenv("BPL_JVM_MEMORY_REQUEST_FILE", path)
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).NotTo(ContainSubstring("Using memory request"))
+				})
+			})
+
 			context("low-profile mode (container < 1G)", func() {
-				it("emits a warning when memory is below threshold", func() {
+				it("activates low profile by default", func() {
 					Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 512*calc.Mebi, 10), 0600)).To(Succeed())
 
 					var logOutput strings.Builder
 					m.Logger = log.NewPaketoLogger(&logOutput)
 
-					// Without low-profile scaling, 512M is too small for unscaled defaults, so Execute returns an error.
-					// The warning should still have been emitted before the calculation failed.
-					_, _ = m.Execute()
-					Expect(logOutput.String()).To(ContainSubstring("WARNING: Container memory is below 1G"))
+					result, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result["JAVA_TOOL_OPTIONS"]).To(HaveSuffix(
+						"-Xss512K -XX:+UseSerialGC -XX:CICompilerCount=2 -Xshare:auto"))
+					Expect(logOutput.String()).To(ContainSubstring(
+						"Applying low memory profile for container below 1G: scaling factor 0.50, -XX:+UseSerialGC -XX:CICompilerCount=2 -Xshare:auto"))
+					Expect(logOutput.String()).NotTo(ContainSubstring("WARNING: Container memory is below 1G"))
 				})
 
-				it("does not activate low profile by default", func() {
-					Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 512*calc.Mebi, 10), 0600)).To(Succeed())
+				it("records the low profile in the report", func() {
+					Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 256*calc.Mebi, 10), 0600)).To(Succeed())
 
-					// Without low-profile scaling, unscaled defaults (240M code cache + 250 * 1M stack)
-					// exceed 512M, so the calculator returns an error about fixed regions being too large.
 					_, err := m.Execute()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("fixed memory regions require"))
+					Expect(err).NotTo(HaveOccurred())
+
+					b, err := os.ReadFile(reportPath)
+					Expect(err).NotTo(HaveOccurred())
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+
+					Expect(r.LowProfile).To(BeTrue())
+					Expect(r.ScalingFactor).To(Equal(0.25))
+					Expect(r.LowProfileFlags).To(Equal([]string{
+						"-XX:+UseSerialGC", "-XX:CICompilerCount=2", "-XX:TieredStopAtLevel=1", "-Xshare:auto"}))
 				})
 
-				context("BPL_LOW_MEMORY_PROFILE_DISABLED=false", func() {
+				context("BPL_LOW_MEMORY_PROFILE_DISABLED=true", func() {
 					it.Before(func() {
-						t.Setenv("BPL_LOW_MEMORY_PROFILE_DISABLED", "false")
+						t.Setenv("BPL_LOW_MEMORY_PROFILE_DISABLED", "true")
 					})
 
-					it("emits a warning even when low profile is enabled", func() {
+					it("emits a warning and does not activate low profile", func() {
 						Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 512*calc.Mebi, 10), 0600)).To(Succeed())
 
 						var logOutput strings.Builder
 						m.Logger = log.NewPaketoLogger(&logOutput)
 
+						// Without low-profile scaling, unscaled defaults (240M code cache + 250 * 1M stack)
+						// exceed 512M, so the calculator returns an error about fixed regions being too large.
 						_, err := m.Execute()
-						Expect(err).NotTo(HaveOccurred())
-						Expect(logOutput.String()).To(ContainSubstring("WARNING: Container memory is below 1G"))
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fixed memory regions require"))
+						Expect(logOutput.String()).To(ContainSubstring(
+							"WARNING: Container memory is below 1G and the low memory profile is disabled"))
+					})
+				})
+
+				context("BPL_LOW_MEMORY_PROFILE_DISABLED=false", func() {
+					it.Before(func() {
+						t.Setenv("BPL_LOW_MEMORY_PROFILE_DISABLED", "false")
 					})
 
 					it("scales stack, threads, and code cache at 512M", func() {
@@ -354,6 +741,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 						Expect(opts).To(ContainSubstring("-XX:ReservedCodeCacheSize=60M"))
 						Expect(opts).NotTo(ContainSubstring("-Xss1M"))
 						Expect(opts).NotTo(ContainSubstring("-XX:ReservedCodeCacheSize=240M"))
+						Expect(opts).To(ContainSubstring("-XX:TieredStopAtLevel=1"))
 					})
 
 					context("$BPL_JVM_THREAD_COUNT set with 256M container", func() {
@@ -388,6 +776,24 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 						})
 					})
 
+					context("user sets JIT, GC and CDS flags in $JAVA_TOOL_OPTIONS with 256M container", func() {
+						it.Before(func() {
+							t.Setenv("JAVA_TOOL_OPTIONS", "-XX:+UseParallelGC -XX:CICompilerCount=3 -XX:-TieredCompilation -Xshare:off")
+						})
+
+						it("does not override the user's flags", func() {
+							Expect(os.WriteFile(memoryLimitPathV1, strconv.AppendInt([]byte{}, 256*calc.Mebi, 10), 0600)).To(Succeed())
+
+							result, err := m.Execute()
+							Expect(err).NotTo(HaveOccurred())
+							opts := result["JAVA_TOOL_OPTIONS"]
+							Expect(opts).NotTo(ContainSubstring("-XX:+UseSerialGC"))
+							Expect(opts).NotTo(ContainSubstring("-XX:CICompilerCount=2"))
+							Expect(opts).NotTo(ContainSubstring("-XX:TieredStopAtLevel=1"))
+							Expect(opts).NotTo(ContainSubstring("-Xshare:auto"))
+						})
+					})
+
 					context("user sets -Xmx in $JAVA_TOOL_OPTIONS", func() {
 						it.Before(func() {
 							t.Setenv("JAVA_TOOL_OPTIONS", "-Xmx200M")
@@ -448,13 +854,159 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("limits total memory to container size if V2 set", func() {
-				Expect(os.WriteFile(memoryLimitPathV2, strconv.AppendInt([]byte{}, 11*calc.Gibi, 10), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), strconv.AppendInt([]byte{}, 11*calc.Gibi, 10), 0600)).To(Succeed())
 
 				Expect(m.Execute()).To(Equal(map[string]string{
-					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx11008465K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx10071331K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 				}))
 			})
 
+			context("cgroup v2 hierarchy", func() {
+				var (
+					container  string
+					logOutput  strings.Builder
+					writeLimit = func(dir string, file string, value string) {
+						Expect(os.WriteFile(filepath.Join(dir, file), []byte(value+"\n"), 0600)).To(Succeed())
+					}
+				)
+
+				it.Before(func() {
+					container = filepath.Join(cgroupRoot, "kubepods", "pod", "container")
+					Expect(os.MkdirAll(container, 0755)).To(Succeed())
+					Expect(os.WriteFile(cgroupPath, []byte("0::/kubepods/pod/container\n"), 0600)).To(Succeed())
+
+					writeLimit(cgroupRoot, "memory.max", "max")
+					writeLimit(filepath.Join(cgroupRoot, "kubepods"), "memory.max", strconv.FormatInt(2*calc.Gibi, 10))
+					writeLimit(container, "memory.max", "max")
+					writeLimit(container, "memory.high", "max")
+
+					logOutput.Reset()
+					m.Logger = log.NewPaketoLogger(&logOutput)
+				})
+
+				it("uses the limit of a parent cgroup", func() {
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx1389122K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring(
+						fmt.Sprintf("Using memory limit of 2G from %s", filepath.Join(cgroupRoot, "kubepods", "memory.max"))))
+				})
+
+				it("uses the tightest of memory.max and memory.high", func() {
+					writeLimit(filepath.Join(cgroupRoot, "kubepods", "pod"), "memory.high", strconv.FormatInt(calc.Gibi, 10))
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring(
+						fmt.Sprintf("Using memory limit of 1G from %s", filepath.Join(cgroupRoot, "kubepods", "pod", "memory.high"))))
+				})
+
+				it("uses the root cgroup when the process' cgroup is not visible", func() {
+					Expect(os.WriteFile(cgroupPath, []byte("0::/system.slice/docker.scope\n"), 0600)).To(Succeed())
+					writeLimit(cgroupRoot, "memory.max", strconv.FormatInt(calc.Gibi, 10))
+
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+					Expect(logOutput.String()).To(ContainSubstring(
+						fmt.Sprintf("Using memory limit of 1G from %s", filepath.Join(cgroupRoot, "memory.max"))))
+				})
+
+				it("warns about swap", func() {
+					writeLimit(container, "memory.swap.max", strconv.FormatInt(512*calc.Mebi, 10))
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).To(ContainSubstring(
+						fmt.Sprintf("WARNING: %s allows 512M of swap", filepath.Join(container, "memory.swap.max"))))
+				})
+
+				it("does not warn about disabled swap", func() {
+					writeLimit(container, "memory.swap.max", "0")
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).NotTo(ContainSubstring("swap"))
+				})
+			})
+
+			context("memory calculation report", func() {
+				var readReport = func(path string) helper.MemoryCalculationReport {
+					b, err := os.ReadFile(path)
+					Expect(err).NotTo(HaveOccurred())
+
+					var r helper.MemoryCalculationReport
+					Expect(json.Unmarshal(b, &r)).To(Succeed())
+					return r
+				}
+
+				it("writes the report", func() {
+					t.Setenv("BPL_JVM_HEAD_ROOM", "1")
+					Expect(os.WriteFile(memoryLimitPathV1, []byte(strconv.FormatInt(calc.Gibi, 10)), 0600)).To(Succeed())
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+
+					r := readReport(reportPath)
+					Expect(r.TotalMemory).To(Equal(calc.Gibi))
+					Expect(r.TotalMemorySource).To(Equal(memoryLimitPathV1))
+					Expect(r.HeadRoom).To(Equal(calc.PercentageAmount(1)))
+					Expect(r.ThreadCount).To(Equal(250))
+					Expect(r.ThreadCountProvenance).To(Equal(calc.Default))
+					Expect(r.LoadedClassCount).To(Equal(35))
+					Expect(r.ScalingFactor).To(Equal(1.0))
+					Expect(r.ClassCount).To(Equal(helper.ClassCountInputs{
+						JVM:               100,
+						AdjustmentPercent: 100,
+						LoadFactor:        helper.ClassLoadFactor,
+					}))
+					Expect(r.Regions).To(ContainElement(calc.Region{
+						Name: "head_room", Size: 10737418, Provenance: calc.Calculated,
+					}))
+					Expect(r.Regions).To(ContainElement(calc.Region{
+						Name: "reserved_code_cache", Flag: "-XX:ReservedCodeCacheSize=240M", Size: 240 * calc.Mebi, Provenance: calc.Default,
+					}))
+				})
+
+				it("records user configured regions and the default total memory", func() {
+					t.Setenv("BPL_JVM_LOADED_CLASS_COUNT", "100")
+					t.Setenv("JAVA_TOOL_OPTIONS", "-Xss512k")
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+
+					r := readReport(reportPath)
+					Expect(r.TotalMemorySource).To(Equal("default"))
+					Expect(r.ClassCount).To(Equal(helper.ClassCountInputs{Configured: true}))
+					Expect(r.Flags).NotTo(ContainElement("-Xss512K"))
+					Expect(r.Regions).To(ContainElement(calc.Region{
+						Name: "stack", Flag: "-Xss512K", Size: 250 * 512 * calc.Kibi, Provenance: calc.UserConfigured,
+					}))
+				})
+
+				it("writes the report to $BPL_JVM_MEMORY_REPORT_PATH", func() {
+					path := filepath.Join(t.TempDir(), "report.json")
+					t.Setenv("BPL_JVM_MEMORY_REPORT_PATH", path)
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(readReport(path).TotalMemory).To(Equal(calc.Gibi))
+					Expect(reportPath).NotTo(BeAnExistingFile())
+				})
+
+				it("warns if the report cannot be written", func() {
+					var logOutput strings.Builder
+					m.Logger = log.NewPaketoLogger(&logOutput)
+					m.ReportPath = filepath.Join(reportPath, "missing", "report.json")
+
+					_, err := m.Execute()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(logOutput.String()).To(ContainSubstring("WARNING: Unable to write memory calculation report"))
+				})
+			})
+
 			context("$JAVA_TOOL_OPTIONS", func() {
 				it.Before(func() {
 					t.Setenv("JAVA_TOOL_OPTIONS", "test-java-tool-options")
@@ -462,7 +1014,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 
 				it("returns default options appended to existing $JAVA_TOOL_OPTIONS", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "test-java-tool-options -XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "test-java-tool-options -XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
 			})
@@ -477,17 +1029,24 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(c).To(Equal(2))
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": fmt.Sprintf("-javaagent:%s -XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M", filepath.Join("../count/testdata", "stub-dependency.jar")),
+						"JAVA_TOOL_OPTIONS": fmt.Sprintf("-javaagent:%s -XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M", filepath.Join("../count/testdata", "stub-dependency.jar")),
 					}))
 				})
 
+				it("counts classes of agent jars with options", func() {
+					t.Setenv("JAVA_TOOL_OPTIONS", fmt.Sprintf("-javaagent:%s=test-key=test-value", filepath.Join("../count/testdata", "stub-dependency.jar")))
+					c, err := m.CountAgentClasses(os.Getenv("JAVA_TOOL_OPTIONS"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(c).To(Equal(2))
+				})
+
 				it("skips counting classes if agent jar(s) supplied via $JAVA_TOOL_OPTIONS can't be found", func() {
 					t.Setenv("JAVA_TOOL_OPTIONS", fmt.Sprintf("-javaagent:!abc -javaagent:%s", filepath.Join("../count/testdata", "stub-dependency.jar")))
 					c, err := m.CountAgentClasses(os.Getenv("JAVA_TOOL_OPTIONS"))
 					Expect(err).NotTo(HaveOccurred())
 					Expect(c).To(Equal(2))
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": fmt.Sprintf("-javaagent:!abc -javaagent:%s -XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M", filepath.Join("../count/testdata", "stub-dependency.jar")),
+						"JAVA_TOOL_OPTIONS": fmt.Sprintf("-javaagent:!abc -javaagent:%s -XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M", filepath.Join("../count/testdata", "stub-dependency.jar")),
 					}))
 				})
 
@@ -497,7 +1056,7 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(c).To(Equal(0))
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": " -XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": " -XX:MaxDirectMemorySize=10M -Xmx497832K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
 
@@ -507,19 +1066,31 @@ func testMemoryCalculator(t *testing.T, context spec.G, it spec.S) {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(c).To(Equal(2))
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxMetaspaceSize=20000K -javaagent:../count/testdata/stub-dependency.jar -XX:MaxDirectMemorySize=10M -Xmx516576K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxMetaspaceSize=20000K -javaagent:../count/testdata/stub-dependency.jar -XX:MaxDirectMemorySize=10M -Xmx491702K -XX:ReservedCodeCacheSize=240M -Xss1M",
+					}))
+				})
+			})
+
+			context("user configured with -XX flags", func() {
+				it.Before(func() {
+					t.Setenv("JAVA_TOOL_OPTIONS", "-XX:MaxRAMPercentage=50 -XX:ThreadStackSize=512")
+				})
+
+				it("does not add conflicting flags", func() {
+					Expect(m.Execute()).To(Equal(map[string]string{
+						"JAVA_TOOL_OPTIONS": "-XX:MaxRAMPercentage=50 -XX:ThreadStackSize=512 -XX:MaxDirectMemorySize=10M -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M",
 					}))
 				})
 			})
 
 			context("user configured", func() {
 				it.Before(func() {
-					t.Setenv("JAVA_TOOL_OPTIONS", "-XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M")
+					t.Setenv("JAVA_TOOL_OPTIONS", "-XX:MaxDirectMemorySize=10M -Xmx497798K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M")
 				})
 
 				it("returns default options appended to existing $JAVA_TOOL_OPTIONS", func() {
 					Expect(m.Execute()).To(Equal(map[string]string{
-						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx522705K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
+						"JAVA_TOOL_OPTIONS": "-XX:MaxDirectMemorySize=10M -Xmx497798K -XX:MaxMetaspaceSize=13870K -XX:ReservedCodeCacheSize=240M -Xss1M",
 					}))
 				})
 			})
@@ -61,6 +61,20 @@ func (o OpenSSLCertificateLoader) Execute() (map[string]string, error) {
 		return nil, fmt.Errorf("$BPI_JVM_CACERTS must be set")
 	}
 
+	o.CertificateLoader.IncludeAllFiles = sherpa.ResolveBool("BPL_JVM_CACERTS_INCLUDE_ALL_FILES")
+
+	if expected, ok := os.LookupEnv("BPI_JVM_CACERTS_FINGERPRINT"); ok {
+		actual, err := o.CertificateLoader.Fingerprint()
+		if err != nil {
+			return nil, fmt.Errorf("unable to fingerprint certificates\n%w", err)
+		}
+
+		if actual == expected {
+			o.Logger.Body("Container CA certificates are unchanged since build, skipping truststore update")
+			return nil, nil
+		}
+	}
+
 	trustStoreWriteable := unix.Access(trustStore, unix.W_OK) == nil
 
 	var opts map[string]string
@@ -75,7 +89,8 @@ func (o OpenSSLCertificateLoader) Execute() (map[string]string, error) {
 		opts = tmpOpts
 	}
 
-	if err := o.CertificateLoader.Load(trustStore, "changeit"); err != nil {
+	password := sherpa.GetEnvWithDefault("BPL_JVM_CACERTS_PASSWORD", jvmvendors.DefaultCertPassword)
+	if err := o.CertificateLoader.Load(trustStore, password); err != nil {
 		return nil, fmt.Errorf("unable to load certificates\n%w", err)
 	}
 
@@ -98,6 +98,42 @@ func testOpenSSLCertificateLoader(t *testing.T, context spec.G, it spec.S) {
 			Expect(ks.Aliases()).To(HaveLen(3))
 		})
 
+		it("skips unchanged certificates", func() {
+			fingerprint, err := cl.Fingerprint()
+			Expect(err).NotTo(HaveOccurred())
+			t.Setenv("BPI_JVM_CACERTS_FINGERPRINT", fingerprint)
+
+			o := helper.OpenSSLCertificateLoader{CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+			Expect(o.Execute()).To(BeNil())
+
+			in, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = in.Close() }()
+
+			ks := keystore.New()
+			err = ks.Load(in, []byte("changeit"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks.Aliases()).To(HaveLen(1))
+		})
+
+		it("loads changed certificates", func() {
+			t.Setenv("BPI_JVM_CACERTS_FINGERPRINT", "another-fingerprint")
+
+			o := helper.OpenSSLCertificateLoader{CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+			Expect(o.Execute()).To(BeNil())
+
+			in, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = in.Close() }()
+
+			ks := keystore.New()
+			err = ks.Load(in, []byte("changeit"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks.Aliases()).To(HaveLen(3))
+		})
+
 		internal.SkipIfRoot(it, "does use temp keystore if keystore is read-only", func() {
 			Expect(os.Chmod(path, 0555)).To(Succeed())
 
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+)
+
+const (
+	// NonRequestThreadCount is the number of threads, such as those of the JVM, its garbage collector and JIT compilers
+	// and of libraries, that an application uses in addition to its request threads. It is the difference between the
+	// default thread count and the 200 request threads of Tomcat's default pool.
+	NonRequestThreadCount = calc.DefaultThreadCount - 200
+
+	VirtualThreadsProperty = "spring.threads.virtual.enabled"
+)
+
+var (
+	// ServerThreadProperties are the properties, current and legacy, that set the maximum size of the request thread
+	// pool of Tomcat, Jetty and Undertow.
+	ServerThreadProperties = []string{
+		"server.tomcat.threads.max",
+		"server.tomcat.max-threads",
+		"server.jetty.threads.max",
+		"server.jetty.max-threads",
+		"server.undertow.threads.worker",
+		"server.undertow.worker-threads",
+	}
+
+	// ApplicationConfigDirectories are the directories, relative to the application and from lowest to highest
+	// precedence, that Spring Boot configuration files are read from.
+	ApplicationConfigDirectories = []string{
+		"BOOT-INF/classes",
+		"BOOT-INF/classes/config",
+		"WEB-INF/classes",
+		"WEB-INF/classes/config",
+		".",
+		"config",
+	}
+
+	PlaceholderRE = regexp.MustCompile(`^\$\{([^}:]+)(?::([^}]*))?}$`)
+)
+
+// InferredThreadCount is a thread count inferred from the property of an application's configuration in source.
+type InferredThreadCount struct {
+	calc.ThreadCount
+	Property string
+	Source   string
+}
+
+// InferThreadCount infers the number of threads an application uses from its Spring Boot configuration. When virtual
+// threads are enabled, on Java 21 and later, requests run on a carrier thread per processor rather than on a pool of
+// request threads. Otherwise, the size of the server's request thread pool is used. ok is false if neither is
+// configured.
+func InferThreadCount(applicationPath string, javaVersion int, processors int) (InferredThreadCount, bool, error) {
+	config, err := readApplicationConfig(applicationPath)
+	if err != nil {
+		return InferredThreadCount{}, false, fmt.Errorf("unable to read application configuration\n%w", err)
+	}
+
+	if v, source, ok := config.lookup(VirtualThreadsProperty); ok && javaVersion >= 21 {
+		if b, err := strconv.ParseBool(v); err == nil && b {
+			return InferredThreadCount{
+				ThreadCount: calc.ThreadCount{Value: NonRequestThreadCount + processors, Provenance: calc.Calculated},
+				Property:    VirtualThreadsProperty,
+				Source:      source,
+			}, true, nil
+		}
+	}
+
+	for _, p := range ServerThreadProperties {
+		v, source, ok := config.lookup(p)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return InferredThreadCount{}, false, fmt.Errorf("%s=%s in %s is not a positive integer", p, v, source)
+		}
+
+		return InferredThreadCount{
+			ThreadCount: calc.ThreadCount{Value: NonRequestThreadCount + n, Provenance: calc.Calculated},
+			Property:    p,
+			Source:      source,
+		}, true, nil
+	}
+
+	return InferredThreadCount{}, false, nil
+}
+
+type applicationProperty struct {
+	value  string
+	source string
+}
+
+// applicationConfig is the flattened configuration of an application, keyed by property name.
+type applicationConfig map[string]applicationProperty
+
+// readApplicationConfig reads the configuration files in ApplicationConfigDirectories. As in Spring Boot, properties
+// files take precedence over YAML files in the same directory and profile specific YAML documents are ignored.
+func readApplicationConfig(applicationPath string) (applicationConfig, error) {
+	config := applicationConfig{}
+
+	for _, d := range ApplicationConfigDirectories {
+		for _, f := range []string{"application.yaml", "application.yml", "application.properties"} {
+			path := filepath.Join(applicationPath, d, f)
+
+			b, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("unable to read %s\n%w", path, err)
+			}
+
+			var values map[string]string
+			if filepath.Ext(f) == ".properties" {
+				values, err = parseApplicationProperties(b)
+			} else {
+				values, err = parseApplicationYAML(b)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s\n%w", path, err)
+			}
+
+			for k, v := range values {
+				config[k] = applicationProperty{value: v, source: path}
+			}
+		}
+	}
+
+	return config, nil
+}
+
+func parseApplicationProperties(b []byte) (map[string]string, error) {
+	l := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+
+	p, err := l.LoadBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Map(), nil
+}
+
+func parseApplicationYAML(b []byte) (map[string]string, error) {
+	values := map[string]string{}
+
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc map[string]any
+		if err := d.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		flat := map[string]string{}
+		flattenYAML("", doc, flat)
+
+		if _, ok := flat["spring.config.activate.on-profile"]; ok {
+			continue
+		}
+		if _, ok := flat["spring.profiles"]; ok {
+			continue
+		}
+
+		for k, v := range flat {
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
+
+func flattenYAML(prefix string, value any, values map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, c := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenYAML(k, c, values)
+		}
+	case []any:
+		for i, c := range v {
+			flattenYAML(fmt.Sprintf("%s[%d]", prefix, i), c, values)
+		}
+	case nil:
+	default:
+		values[prefix] = fmt.Sprint(v)
+	}
+}
+
+// lookup returns the value of a property and its source. As in Spring Boot, environment variables, such as
+// $SERVER_TOMCAT_THREADS_MAX for server.tomcat.threads.max, take precedence over configuration files. Placeholders
+// are resolved against the environment and the configuration, falling back to their default.
+func (a applicationConfig) lookup(name string) (string, string, bool) {
+	return a.resolve(name, 0)
+}
+
+func (a applicationConfig) resolve(name string, depth int) (string, string, bool) {
+	// placeholders that refer to each other are not resolved
+	if depth > 10 {
+		return "", "", false
+	}
+
+	env := strings.ToUpper(strings.NewReplacer(".", "_", "-", "").Replace(name))
+	if v, ok := os.LookupEnv(env); ok {
+		return v, "$" + env, true
+	}
+
+	p, ok := a[name]
+	if !ok {
+		return "", "", false
+	}
+
+	g := PlaceholderRE.FindStringSubmatch(strings.TrimSpace(p.value))
+	if g == nil {
+		return strings.TrimSpace(p.value), p.source, true
+	}
+
+	if v, source, ok := a.resolve(g[1], depth+1); ok {
+		return v, source, true
+	}
+	if strings.Contains(p.value, ":") {
+		return g[2], p.source, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/jvm-vendors/calc"
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testThreadCount(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		applicationPath string
+
+		write = func(path string, content string) string {
+			path = filepath.Join(applicationPath, path)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			return path
+		}
+	)
+
+	it.Before(func() {
+		applicationPath = t.TempDir()
+	})
+
+	it("does not infer a thread count without configuration", func() {
+		_, ok, err := helper.InferThreadCount(applicationPath, 21, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	it("infers the thread count from application.properties in BOOT-INF/classes", func() {
+		path := write("BOOT-INF/classes/application.properties", "server.tomcat.threads.max=800\n")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c).To(Equal(helper.InferredThreadCount{
+			ThreadCount: calc.ThreadCount{Value: 850, Provenance: calc.Calculated},
+			Property:    "server.tomcat.threads.max",
+			Source:      path,
+		}))
+	})
+
+	it("infers the thread count from nested application.yml", func() {
+		path := write("application.yml", "server:\n  jetty:\n    threads:\n      max: 400\n")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Value).To(Equal(450))
+		Expect(c.Property).To(Equal("server.jetty.threads.max"))
+		Expect(c.Source).To(Equal(path))
+	})
+
+	it("infers the thread count from legacy properties", func() {
+		write("application.properties", "server.undertow.worker-threads=64\n")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Value).To(Equal(114))
+	})
+
+	it("ignores profile specific YAML documents", func() {
+		write("application.yaml", "server.tomcat.threads.max: 100\n---\nspring.config.activate.on-profile: prod\nserver.tomcat.threads.max: 1000\n")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Value).To(Equal(150))
+	})
+
+	it("prefers config directories and properties files", func() {
+		write("BOOT-INF/classes/application.properties", "server.tomcat.threads.max=100\n")
+		write("config/application.yml", "server.tomcat.threads.max: 200\n")
+		path := write("config/application.properties", "server.tomcat.threads.max=300\n")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Value).To(Equal(350))
+		Expect(c.Source).To(Equal(path))
+	})
+
+	it("prefers the environment", func() {
+		write("application.properties", "server.tomcat.threads.max=800\n")
+		t.Setenv("SERVER_TOMCAT_THREADS_MAX", "100")
+
+		c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Value).To(Equal(150))
+		Expect(c.Source).To(Equal("$SERVER_TOMCAT_THREADS_MAX"))
+	})
+
+	context("placeholders", func() {
+		it("resolves placeholders from the environment", func() {
+			write("application.properties", "server.tomcat.threads.max=${TOMCAT_THREADS:800}\n")
+			t.Setenv("TOMCAT_THREADS", "300")
+
+			c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(c.Value).To(Equal(350))
+		})
+
+		it("falls back to the default of placeholders", func() {
+			write("application.properties", "server.tomcat.threads.max=${TOMCAT_THREADS:800}\n")
+
+			c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(c.Value).To(Equal(850))
+		})
+
+		it("does not infer a thread count from unresolvable placeholders", func() {
+			write("application.properties", "server.tomcat.threads.max=${server.tomcat.threads.max}\n")
+
+			_, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	it("returns error if the thread pool size is not a positive integer", func() {
+		write("application.properties", "server.tomcat.threads.max=lots\n")
+
+		_, _, err := helper.InferThreadCount(applicationPath, 17, 4)
+		Expect(err).To(MatchError(HavePrefix("server.tomcat.threads.max=lots in ")))
+	})
+
+	context("virtual threads", func() {
+		it.Before(func() {
+			write("BOOT-INF/classes/application.properties", "spring.threads.virtual.enabled=true\nserver.tomcat.threads.max=800\n")
+		})
+
+		it("uses a carrier thread per processor on Java 21", func() {
+			c, ok, err := helper.InferThreadCount(applicationPath, 21, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(c.ThreadCount).To(Equal(calc.ThreadCount{Value: 54, Provenance: calc.Calculated}))
+			Expect(c.Property).To(Equal(helper.VirtualThreadsProperty))
+		})
+
+		it("uses the request thread pool before Java 21", func() {
+			c, ok, err := helper.InferThreadCount(applicationPath, 17, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(c.Value).To(Equal(850))
+		})
+	})
+}
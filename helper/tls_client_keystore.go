@@ -0,0 +1,178 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/bindings"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+	"golang.org/x/sys/unix"
+	"software.sslmate.com/src/go-pkcs12"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+)
+
+const TLSBindingType = "tls"
+
+var TmpClientKeyStore = filepath.Join(os.TempDir(), "client-keystore.p12")
+
+// TLSClientKeystore builds a PKCS12 client identity keystore for mutual TLS from a binding of type tls. It must run
+// after OpenSSLCertificateLoader so that a CA added to the truststore is not lost when that helper copies a read-only
+// truststore.
+type TLSClientKeystore struct {
+	Bindings          libcnb.Bindings
+	CertificateLoader jvmvendors.CertificateLoader
+	Logger            log.Logger
+}
+
+func (t TLSClientKeystore) Execute() (map[string]string, error) {
+	b, ok, err := bindings.ResolveOne(t.Bindings, bindings.OfType(TLSBindingType))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve binding %s\n%w", TLSBindingType, err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	certFile, certOk := b.SecretFilePath("tls.crt")
+	keyFile, keyOk := b.SecretFilePath("tls.key")
+	if !certOk || !keyOk {
+		return nil, fmt.Errorf("binding %s must contain tls.crt and tls.key", b.Name)
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := writeKeyStore(b, certFile, keyFile, TmpClientKeyStore, password)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Logger.Bodyf("Using client certificate %s from binding %s", cert.Subject, b.Name)
+
+	// JSSE only reads the password from a system property, so it is visible in the JVM's "Picked up
+	// JAVA_TOOL_OPTIONS" line. It is random for each launch and the keystore is only readable by its owner.
+	opts := []string{
+		fmt.Sprintf("-Djavax.net.ssl.keyStore=%s", TmpClientKeyStore),
+		"-Djavax.net.ssl.keyStoreType=PKCS12",
+		fmt.Sprintf("-Djavax.net.ssl.keyStorePassword=%s", password),
+	}
+
+	if caFile, ok := b.SecretFilePath("ca.crt"); ok {
+		trustStoreOpts, err := t.loadCA(caFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, trustStoreOpts...)
+	}
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": sherpa.AppendToEnvVar("JAVA_TOOL_OPTIONS", " ", opts...)}, nil
+}
+
+// loadCA adds the binding's CA to the truststore in use, copying it to a writable location first if necessary.
+func (t TLSClientKeystore) loadCA(caFile string) ([]string, error) {
+	trustStore, ok := activeTrustStore()
+	if !ok {
+		return nil, fmt.Errorf("$BPI_JVM_CACERTS must be set")
+	}
+
+	var opts []string
+	if unix.Access(trustStore, unix.W_OK) != nil {
+		in, err := os.Open(trustStore)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open trust store %s\n%w", trustStore, err)
+		}
+		defer func() { _ = in.Close() }()
+
+		if err := sherpa.CopyFile(in, TmpTrustStore); err != nil {
+			return nil, fmt.Errorf("unable to copy trust store (%s, %s)\n%w", trustStore, TmpTrustStore, err)
+		}
+
+		trustStore = TmpTrustStore
+		opts = append(opts, fmt.Sprintf("-Djavax.net.ssl.trustStore=%s", TmpTrustStore))
+	}
+
+	cl := t.CertificateLoader
+	cl.CertFile = caFile
+	cl.CertDirs = nil
+
+	password := sherpa.GetEnvWithDefault("BPL_JVM_CACERTS_PASSWORD", jvmvendors.DefaultCertPassword)
+	if err := cl.Load(trustStore, password); err != nil {
+		return nil, fmt.Errorf("unable to load certificates from %s\n%w", caFile, err)
+	}
+
+	return opts, nil
+}
+
+// writeKeyStore writes the key pair of a binding to a PKCS12 keystore at path and returns its certificate.
+func writeKeyStore(b libcnb.Binding, certFile string, keyFile string, path string, password string) (*x509.Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load key pair from binding %s\n%w", b.Name, err)
+	}
+
+	var certs []*x509.Certificate
+	for _, c := range pair.Certificate {
+		cert, err := x509.ParseCertificate(c)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate from %s\n%w", certFile, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	data, err := pkcs12.Modern2023.Encode(pair.PrivateKey, certs[0], certs[1:], password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode keystore %s\n%w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("unable to write keystore %s\n%w", path, err)
+	}
+
+	return certs[0], nil
+}
+
+// activeTrustStore returns the truststore configured in $JAVA_TOOL_OPTIONS by an earlier helper, falling back to the
+// JVM's own truststore.
+func activeTrustStore() (string, bool) {
+	trustStore, ok := os.LookupEnv("BPI_JVM_CACERTS")
+	for _, opt := range strings.Fields(os.Getenv("JAVA_TOOL_OPTIONS")) {
+		if s, found := strings.CutPrefix(opt, "-Djavax.net.ssl.trustStore="); found {
+			trustStore, ok = s, true
+		}
+	}
+	return trustStore, ok
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate keystore password\n%w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/sclevine/spec"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testTLSClientKeystore(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cl = jvmvendors.CertificateLoader{Logger: log.NewDiscardLogger()}
+
+		bindingPath string
+		trustStore  string
+	)
+
+	writeKeyPair := func(pkcs8 bool) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "test-client"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+
+		var block *pem.Block
+		if pkcs8 {
+			b, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			block = &pem.Block{Type: "PRIVATE KEY", Bytes: b}
+		} else {
+			b, err := x509.MarshalECPrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}
+		}
+
+		Expect(os.WriteFile(filepath.Join(bindingPath, "tls.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(bindingPath, "tls.key"), pem.EncodeToMemory(block), 0644)).To(Succeed())
+	}
+
+	binding := func() libcnb.Bindings {
+		b, err := libcnb.NewBindingFromPath(bindingPath)
+		Expect(err).NotTo(HaveOccurred())
+		return libcnb.Bindings{b}
+	}
+
+	keyStorePassword := func(opts string) string {
+		m := regexp.MustCompile(`-Djavax.net.ssl.keyStorePassword=(\S+)`).FindStringSubmatch(opts)
+		Expect(m).To(HaveLen(2))
+		return m[1]
+	}
+
+	it.Before(func() {
+		bindingPath = t.TempDir()
+		Expect(os.WriteFile(filepath.Join(bindingPath, "type"), []byte("tls"), 0644)).To(Succeed())
+
+		in, err := os.Open(filepath.Join("testdata", "test-keystore.jks"))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = in.Close() }()
+
+		out, err := os.CreateTemp("", "tls-client-keystore")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = out.Close() }()
+
+		_, err = io.Copy(out, in)
+		Expect(err).NotTo(HaveOccurred())
+
+		trustStore = out.Name()
+		t.Setenv("BPI_JVM_CACERTS", trustStore)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(trustStore)).To(Succeed())
+		_ = os.Remove(helper.TmpClientKeyStore)
+	})
+
+	it("does nothing without a tls binding", func() {
+		k := helper.TLSClientKeystore{CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+		Expect(k.Execute()).To(BeNil())
+	})
+
+	it("returns error if the binding has no key", func() {
+		Expect(os.WriteFile(filepath.Join(bindingPath, "tls.crt"), []byte{}, 0644)).To(Succeed())
+
+		k := helper.TLSClientKeystore{Bindings: binding(), CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+		_, err := k.Execute()
+		Expect(err).To(MatchError(fmt.Sprintf("binding %s must contain tls.crt and tls.key", filepath.Base(bindingPath))))
+	})
+
+	it("builds a client keystore from an EC key", func() {
+		writeKeyPair(false)
+
+		k := helper.TLSClientKeystore{Bindings: binding(), CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+		env, err := k.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env["JAVA_TOOL_OPTIONS"]).To(HavePrefix(fmt.Sprintf("-Djavax.net.ssl.keyStore=%s -Djavax.net.ssl.keyStoreType=PKCS12 -Djavax.net.ssl.keyStorePassword=", helper.TmpClientKeyStore)))
+		Expect(env["JAVA_TOOL_OPTIONS"]).NotTo(ContainSubstring("trustStore"))
+
+		data, err := os.ReadFile(helper.TmpClientKeyStore)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, cert, err := pkcs12.Decode(data, keyStorePassword(env["JAVA_TOOL_OPTIONS"]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+		Expect(cert.Subject.CommonName).To(Equal("test-client"))
+	})
+
+	it("builds a client keystore from a PKCS8 key", func() {
+		writeKeyPair(true)
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Xmx1G")
+
+		k := helper.TLSClientKeystore{Bindings: binding(), CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+		env, err := k.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env["JAVA_TOOL_OPTIONS"]).To(HavePrefix("-Xmx1G -Djavax.net.ssl.keyStore="))
+
+		data, err := os.ReadFile(helper.TmpClientKeyStore)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = pkcs12.Decode(data, keyStorePassword(env["JAVA_TOOL_OPTIONS"]))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("adds ca.crt to the truststore", func() {
+		writeKeyPair(false)
+		ca, err := os.ReadFile(filepath.Join("testdata", "certificates", "certificate-1.pem"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(bindingPath, "ca.crt"), ca, 0644)).To(Succeed())
+
+		k := helper.TLSClientKeystore{Bindings: binding(), CertificateLoader: cl, Logger: log.NewDiscardLogger()}
+
+		_, err = k.Execute()
+		Expect(err).NotTo(HaveOccurred())
+
+		in, err := os.Open(trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = in.Close() }()
+
+		ks := keystore.New()
+		Expect(ks.Load(in, []byte("changeit"))).To(Succeed())
+		Expect(ks.Aliases()).To(HaveLen(2))
+	})
+}
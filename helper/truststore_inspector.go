@@ -0,0 +1,240 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak/v2/sherpa"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+)
+
+const (
+	TruststoreSourceContainer = "container"
+	TruststoreSourceVendor    = "vendor"
+)
+
+// TruststoreInspector lists the entries of a JVM truststore, or the differences between two truststores, so that
+// TLS failures can be diagnosed in images that do not contain keytool.
+type TruststoreInspector struct {
+	CertificateLoader jvmvendors.CertificateLoader
+	Out               io.Writer
+}
+
+type TruststoreEntry struct {
+	Alias       string    `json:"alias"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotAfter    time.Time `json:"not_after"`
+	Fingerprint string    `json:"sha256_fingerprint"`
+	Source      string    `json:"source"`
+}
+
+type TruststoreDiff struct {
+	Added   []TruststoreEntry `json:"added"`
+	Removed []TruststoreEntry `json:"removed"`
+}
+
+const truststoreUsage = `Usage:
+  truststore list [-format table|json] [-password PASSWORD] [TRUSTSTORE]
+  truststore diff [-format table|json] [-password PASSWORD] TRUSTSTORE TRUSTSTORE
+
+When TRUSTSTORE is omitted, the truststore configured for the JVM is used.`
+
+func (t TruststoreInspector) Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected list or diff\n%s", truststoreUsage)
+	}
+
+	flags := flag.NewFlagSet("truststore "+args[0], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "table", "")
+	password := flags.String("password", sherpa.GetEnvWithDefault("BPL_JVM_CACERTS_PASSWORD", jvmvendors.DefaultCertPassword), "")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w\n%s", err, truststoreUsage)
+	}
+
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unsupported format %s, expected table or json", *format)
+	}
+
+	t.CertificateLoader.IncludeAllFiles = sherpa.ResolveBool("BPL_JVM_CACERTS_INCLUDE_ALL_FILES")
+
+	switch args[0] {
+	case "list":
+		var path string
+		switch flags.NArg() {
+		case 0:
+			s, ok := activeTrustStore()
+			if !ok {
+				return fmt.Errorf("$BPI_JVM_CACERTS must be set when no truststore is given")
+			}
+			path = s
+		case 1:
+			path = flags.Arg(0)
+		default:
+			return fmt.Errorf("expected at most one truststore\n%s", truststoreUsage)
+		}
+
+		entries, err := t.Entries(path, *password)
+		if err != nil {
+			return err
+		}
+
+		if *format == "json" {
+			return t.writeJSON(entries)
+		}
+		return t.writeTable(entries, nil)
+
+	case "diff":
+		if flags.NArg() != 2 {
+			return fmt.Errorf("expected two truststores\n%s", truststoreUsage)
+		}
+
+		d, err := t.Diff(flags.Arg(0), flags.Arg(1), *password)
+		if err != nil {
+			return err
+		}
+
+		if *format == "json" {
+			return t.writeJSON(d)
+		}
+
+		entries := append(slices.Clone(d.Removed), d.Added...)
+		marks := slices.Repeat([]string{"- "}, len(d.Removed))
+		marks = append(marks, slices.Repeat([]string{"+ "}, len(d.Added))...)
+		return t.writeTable(entries, marks)
+
+	default:
+		return fmt.Errorf("unsupported command %s\n%s", args[0], truststoreUsage)
+	}
+}
+
+// Entries returns the certificates in a truststore. An entry's source is container if the certificate is also one
+// of the container CA certificates, otherwise it is assumed to have been shipped by the JVM vendor.
+func (t TruststoreInspector) Entries(path string, password string) ([]TruststoreEntry, error) {
+	ks, err := jvmvendors.DetectKeystore(path, password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read truststore %s\n%w", path, err)
+	}
+
+	entries, err := ks.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list entries of %s\n%w", path, err)
+	}
+
+	container, err := t.CertificateLoader.CertificateFingerprints()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fingerprint container certificates\n%w", err)
+	}
+
+	var out []TruststoreEntry
+	for _, e := range entries {
+		sum := sha256.Sum256(e.Certificate.Raw)
+		fingerprint := hex.EncodeToString(sum[:])
+
+		source := TruststoreSourceVendor
+		if _, found := slices.BinarySearch(container, fingerprint); found {
+			source = TruststoreSourceContainer
+		}
+
+		out = append(out, TruststoreEntry{
+			Alias:       e.Alias,
+			Subject:     e.Certificate.Subject.String(),
+			Issuer:      e.Certificate.Issuer.String(),
+			NotAfter:    e.Certificate.NotAfter.UTC(),
+			Fingerprint: fingerprint,
+			Source:      source,
+		})
+	}
+
+	return out, nil
+}
+
+// Diff returns the certificates that are only in one of the two truststores, compared by fingerprint.
+func (t TruststoreInspector) Diff(from string, to string, password string) (TruststoreDiff, error) {
+	a, err := t.Entries(from, password)
+	if err != nil {
+		return TruststoreDiff{}, err
+	}
+
+	b, err := t.Entries(to, password)
+	if err != nil {
+		return TruststoreDiff{}, err
+	}
+
+	contains := func(entries []TruststoreEntry, fingerprint string) bool {
+		return slices.ContainsFunc(entries, func(e TruststoreEntry) bool { return e.Fingerprint == fingerprint })
+	}
+
+	d := TruststoreDiff{Added: []TruststoreEntry{}, Removed: []TruststoreEntry{}}
+	for _, e := range a {
+		if !contains(b, e.Fingerprint) {
+			d.Removed = append(d.Removed, e)
+		}
+	}
+	for _, e := range b {
+		if !contains(a, e.Fingerprint) {
+			d.Added = append(d.Added, e)
+		}
+	}
+
+	return d, nil
+}
+
+func (t TruststoreInspector) writeJSON(v any) error {
+	e := json.NewEncoder(t.Out)
+	e.SetIndent("", "  ")
+	if err := e.Encode(v); err != nil {
+		return fmt.Errorf("unable to encode JSON\n%w", err)
+	}
+	return nil
+}
+
+// writeTable writes entries as a table, with each row prefixed by the matching mark when marks are given.
+func (t TruststoreInspector) writeTable(entries []TruststoreEntry, marks []string) error {
+	w := tabwriter.NewWriter(t.Out, 0, 0, 2, ' ', 0)
+
+	header := "ALIAS\tSUBJECT\tISSUER\tEXPIRES\tSHA-256 FINGERPRINT\tSOURCE\n"
+	if marks != nil {
+		header = "  " + header
+	}
+	_, _ = io.WriteString(w, header)
+
+	for i, e := range entries {
+		mark := ""
+		if marks != nil {
+			mark = marks[i]
+		}
+		_, _ = fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", mark, e.Alias, e.Subject, e.Issuer,
+			e.NotAfter.Format(time.DateOnly), e.Fingerprint, e.Source)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("unable to write table\n%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+
+	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+	"github.com/paketo-buildpacks/jvm-vendors/helper"
+)
+
+func testTruststoreInspector(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cl = jvmvendors.CertificateLoader{
+			CertFile: filepath.Join("testdata", "certificates", "certificate-2.crt"),
+			Logger:   log.NewDiscardLogger(),
+		}
+
+		out       *bytes.Buffer
+		inspector helper.TruststoreInspector
+		vendor    string
+		container string
+	)
+
+	it.Before(func() {
+		in, err := os.ReadFile(filepath.Join("testdata", "test-keystore.jks"))
+		Expect(err).NotTo(HaveOccurred())
+
+		vendor = filepath.Join(t.TempDir(), "vendor.jks")
+		Expect(os.WriteFile(vendor, in, 0644)).To(Succeed())
+
+		container = filepath.Join(t.TempDir(), "container.jks")
+		Expect(os.WriteFile(container, in, 0644)).To(Succeed())
+		Expect(cl.Load(container, "changeit")).To(Succeed())
+
+		out = &bytes.Buffer{}
+		inspector = helper.TruststoreInspector{CertificateLoader: cl, Out: out}
+	})
+
+	it("lists entries as JSON", func() {
+		Expect(inspector.Run([]string{"list", "-format", "json", container})).To(Succeed())
+
+		var entries []helper.TruststoreEntry
+		Expect(json.Unmarshal(out.Bytes(), &entries)).To(Succeed())
+		Expect(entries).To(HaveLen(2))
+
+		sources := map[string]string{}
+		for _, e := range entries {
+			Expect(e.Fingerprint).To(HaveLen(64))
+			Expect(e.NotAfter.IsZero()).To(BeFalse())
+			sources[e.Source] = e.Alias
+		}
+		Expect(sources).To(HaveKey(helper.TruststoreSourceVendor))
+		Expect(sources).To(HaveKeyWithValue(helper.TruststoreSourceContainer, cl.CertFile+"-0"))
+	})
+
+	it("lists entries of the JVM truststore as a table", func() {
+		t.Setenv("BPI_JVM_CACERTS", vendor)
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Djavax.net.ssl.trustStore="+container)
+
+		Expect(inspector.Run([]string{"list"})).To(Succeed())
+
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(3))
+		Expect(string(lines[0])).To(MatchRegexp(`^ALIAS\s+SUBJECT\s+ISSUER\s+EXPIRES\s+SHA-256 FINGERPRINT\s+SOURCE$`))
+		Expect(out.String()).To(ContainSubstring(helper.TruststoreSourceContainer))
+	})
+
+	it("diffs truststores as JSON", func() {
+		Expect(inspector.Run([]string{"diff", "-format", "json", vendor, container})).To(Succeed())
+
+		var d helper.TruststoreDiff
+		Expect(json.Unmarshal(out.Bytes(), &d)).To(Succeed())
+		Expect(d.Removed).To(BeEmpty())
+		Expect(d.Added).To(HaveLen(1))
+		Expect(d.Added[0].Source).To(Equal(helper.TruststoreSourceContainer))
+	})
+
+	it("diffs truststores as a table", func() {
+		Expect(inspector.Run([]string{"diff", container, vendor})).To(Succeed())
+
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		Expect(string(lines[1])).To(HavePrefix("- " + cl.CertFile + "-0"))
+	})
+
+	it("returns error for an unsupported format", func() {
+		Expect(inspector.Run([]string{"list", "-format", "xml", vendor})).
+			To(MatchError("unsupported format xml, expected table or json"))
+	})
+
+	it("returns error without a command", func() {
+		Expect(inspector.Run(nil)).To(MatchError(HavePrefix("expected list or diff")))
+	})
+
+	it("returns error when diffing a single truststore", func() {
+		Expect(inspector.Run([]string{"diff", vendor})).To(MatchError(HavePrefix("expected two truststores")))
+	})
+}
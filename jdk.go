@@ -79,7 +79,7 @@ func (j JDK) Contribute(layer *libcnb.Layer) error {
 			return fmt.Errorf("unable to set keystore file permissions\n%w", err)
 		}
 
-		if err := j.CertificateLoader.Load(keyStorePath, "changeit"); err != nil {
+		if err := j.CertificateLoader.Load(keyStorePath, j.CertificateLoader.Password); err != nil {
 			return fmt.Errorf("unable to load certificates\n%w", err)
 		}
 		return nil
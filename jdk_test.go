@@ -37,6 +37,7 @@ func testJDK(t *testing.T, context spec.G, it spec.S) {
 
 		cl = jvmvendors.CertificateLoader{
 			CertDirs: []string{filepath.Join("testdata", "certificates")},
+			Password: "changeit",
 			Logger:   log.NewDiscardLogger(),
 		}
 
@@ -60,7 +60,7 @@ func NewJLink(applicationPath string, exec effect.Executor, args []string, certi
 }
 
 func (j JLink) Contribute(layer *libcnb.Layer) error {
-	return j.LayerContributor.Contribute(layer, func(layer *libcnb.Layer) error {
+	if err := j.LayerContributor.Contribute(layer, func(layer *libcnb.Layer) error {
 		if err := os.RemoveAll(layer.Path); err != nil {
 			return fmt.Errorf("unable to remove jlink layer dir \n%w", err)
 		}
@@ -87,7 +87,7 @@ func (j JLink) Contribute(layer *libcnb.Layer) error {
 			return fmt.Errorf("unable to set keystore file permissions\n%w", err)
 		}
 
-		if err := j.CertificateLoader.Load(cacertsPath, "changeit"); err != nil {
+		if err := j.CertificateLoader.Load(cacertsPath, j.CertificateLoader.Password); err != nil {
 			return fmt.Errorf("unable to load certificates\n%w", err)
 		}
 
@@ -100,6 +100,12 @@ func (j JLink) Contribute(layer *libcnb.Layer) error {
 			layer.LaunchEnvironment.Default("BPI_JVM_CACERTS", cacertsPath)
 			layer.LaunchEnvironment.Default("BPI_JVM_VERSION", j.JavaVersion)
 
+			if fingerprint, err := j.CertificateLoader.Fingerprint(); err != nil {
+				return fmt.Errorf("unable to fingerprint certificates\n%w", err)
+			} else {
+				layer.LaunchEnvironment.Default("BPI_JVM_CACERTS_FINGERPRINT", fingerprint)
+			}
+
 			if c, err := count.Classes(layer.Path); err != nil {
 				return fmt.Errorf("unable to count JVM classes\n%w", err)
 			} else {
@@ -128,7 +134,14 @@ func (j JLink) Contribute(layer *libcnb.Layer) error {
 		}
 
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	if IsLaunchContribution(j.Metadata) {
+		return ContributeApplicationClassCount(layer, j.ApplicationPath, j.Logger)
+	}
+	return nil
 }
 
 func (j JLink) Name() string {
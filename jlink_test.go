@@ -45,6 +45,7 @@ func testJLink(t *testing.T, context spec.G, it spec.S) {
 
 		cl = jvmvendors.CertificateLoader{
 			CertDirs: []string{filepath.Join("testdata", "certificates")},
+			Password: "changeit",
 			Logger:   log.NewDiscardLogger(),
 		}
 
@@ -53,6 +54,7 @@ func testJLink(t *testing.T, context spec.G, it spec.S) {
 
 	it.Before(func() {
 		t.Setenv("BP_JVM_JLINK_ENABLED", "true")
+		ctx.ApplicationPath = t.TempDir()
 		ctx.Layers.Path = t.TempDir()
 	})
 
@@ -97,6 +99,8 @@ func testJLink(t *testing.T, context spec.G, it spec.S) {
 		Expect(e.Args).To(ContainElement("--add-modules"))
 		Expect(e.Args).To(ContainElement("java.se,java.base"))
 		Expect(e.Args).To(ContainElement("--output"))
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("BPI_APP_CLASS_COUNT.default"))
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("BPI_APP_CLASS_COUNT_FINGERPRINT.default"))
 	})
 
 	it("contributes jlink JRE with user provided args & modules", func() {
@@ -96,7 +96,7 @@ func ConfigureJRE(configCtx ConfigJREContext) error {
 		if err := os.Chmod(cacertsPath, 0664); err != nil {
 			return fmt.Errorf("unable to set keystore file permissions\n%w", err)
 		}
-		if err := configCtx.CertificateLoader.Load(cacertsPath, "changeit"); err != nil {
+		if err := configCtx.CertificateLoader.Load(cacertsPath, configCtx.CertificateLoader.Password); err != nil {
 			return fmt.Errorf("unable to load certificates\n%w", err)
 		}
 	} else {
@@ -117,6 +117,15 @@ func ConfigureJRE(configCtx ConfigJREContext) error {
 		configCtx.Layer.LaunchEnvironment.Default("BPI_JVM_CACERTS", cacertsPath)
 		configCtx.Layer.LaunchEnvironment.Default("BPI_JVM_VERSION", configCtx.JavaVersion)
 
+		// record the certificates loaded at build time, so the runtime helper can skip unchanged certificates
+		if !configCtx.SkipCerts {
+			fingerprint, err := configCtx.CertificateLoader.Fingerprint()
+			if err != nil {
+				return fmt.Errorf("unable to fingerprint certificates\n%w", err)
+			}
+			configCtx.Layer.LaunchEnvironment.Default("BPI_JVM_CACERTS_FINGERPRINT", fingerprint)
+		}
+
 		// count the classes in the runtime (used by memory calculator)
 		if c, err := count.Classes(configCtx.JavaHome); err != nil {
 			return fmt.Errorf("unable to count JVM classes\n%w", err)
@@ -166,7 +175,7 @@ func ConfigureJRE(configCtx ConfigJREContext) error {
 }
 
 func (j JRE) Contribute(layer *libcnb.Layer) error {
-	return j.LayerContributor.Contribute(layer, func(layer *libcnb.Layer, artifact *os.File) error {
+	if err := j.LayerContributor.Contribute(layer, func(layer *libcnb.Layer, artifact *os.File) error {
 		j.Logger.Bodyf("Expanding to %s", layer.Path)
 		if err := crush.Extract(artifact, layer.Path, 1); err != nil {
 			return fmt.Errorf("unable to expand JRE\n%w", err)
@@ -184,7 +193,53 @@ func (j JRE) Contribute(layer *libcnb.Layer) error {
 			CertificateLoader: j.CertificateLoader,
 			DistType:          j.DistributionType,
 		})
-	})
+	}); err != nil {
+		return err
+	}
+
+	if IsLaunchContribution(j.Metadata) {
+		return ContributeApplicationClassCount(layer, j.ApplicationPath, j.Logger)
+	}
+	return nil
+}
+
+// applicationBuildFiles are the files of an application that a later buildpack builds from source.
+var applicationBuildFiles = []string{"build.gradle", "build.gradle.kts", "build.sbt", "pom.xml", "project.clj"}
+
+// ContributeApplicationClassCount counts the classes in the application at build time, so that the memory calculator
+// does not need to at launch, along with a fingerprint the memory calculator uses to detect that the application has
+// changed since. It is called on every build, as the application changes independently of the cached JVM layer. The
+// JVM is contributed before the application is built, so only prebuilt applications, such as jars and classes, are
+// counted. Applications built from source are counted at launch.
+func ContributeApplicationClassCount(layer *libcnb.Layer, applicationPath string, logger log.Logger) error {
+	for _, f := range applicationBuildFiles {
+		if _, err := os.Stat(filepath.Join(applicationPath, f)); err == nil {
+			logger.Bodyf("Not counting application classes, the application is built from %s", f)
+			return nil
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("unable to stat %s\n%w", filepath.Join(applicationPath, f), err)
+		}
+	}
+
+	c, err := count.Classes(applicationPath)
+	if err != nil {
+		return fmt.Errorf("unable to count application classes\n%w", err)
+	}
+	if c == 0 {
+		logger.Body("Not counting application classes, the application contains no classes")
+		return nil
+	}
+
+	fingerprint, err := count.Fingerprint(applicationPath)
+	if err != nil {
+		return fmt.Errorf("unable to fingerprint application\n%w", err)
+	}
+
+	logger.Bodyf("Counted %d application classes", c)
+
+	layer.LaunchEnvironment.Default("BPI_APP_CLASS_COUNT", c)
+	layer.LaunchEnvironment.Default("BPI_APP_CLASS_COUNT_FINGERPRINT", fingerprint)
+	return nil
 }
 
 func (j JRE) Name() string {
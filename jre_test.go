@@ -29,6 +29,7 @@ import (
 	"github.com/sclevine/spec"
 
 	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
+	"github.com/paketo-buildpacks/jvm-vendors/count"
 )
 
 func testJRE(t *testing.T, context spec.G, it spec.S) {
@@ -37,6 +38,7 @@ func testJRE(t *testing.T, context spec.G, it spec.S) {
 
 		cl = jvmvendors.CertificateLoader{
 			CertDirs: []string{filepath.Join("testdata", "certificates")},
+			Password: "changeit",
 			Logger:   log.NewDiscardLogger(),
 		}
 
@@ -225,6 +227,9 @@ func testJRE(t *testing.T, context spec.G, it spec.S) {
 		Expect(layer.LayerTypes.Launch).To(BeTrue())
 		Expect(layer.LaunchEnvironment["BPI_APPLICATION_PATH.default"]).To(Equal(ctx.ApplicationPath))
 		Expect(layer.LaunchEnvironment["BPI_JVM_CACERTS.default"]).To(Equal(filepath.Join(layer.Path, "lib", "security", "cacerts")))
+		fingerprint, err := cl.Fingerprint()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.LaunchEnvironment["BPI_JVM_CACERTS_FINGERPRINT.default"]).To(Equal(fingerprint))
 		Expect(layer.LaunchEnvironment["BPI_JVM_CLASS_COUNT.default"]).To(Equal("0"))
 		Expect(layer.LaunchEnvironment["BPI_JVM_EXT_DIR.default"]).To(Equal(filepath.Join(layer.Path, "lib", "ext")))
 		Expect(layer.LaunchEnvironment["BPI_JVM_SECURITY_PROVIDERS.default"]).To(Equal("1|ALPHA"))
@@ -235,6 +240,63 @@ func testJRE(t *testing.T, context spec.G, it spec.S) {
 		Expect(layer.LaunchEnvironment["JAVA_TOOL_OPTIONS.append"]).To(Equal("-XX:+ExitOnOutOfMemoryError"))
 	})
 
+	it("counts application classes for launch", func() {
+		Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "alpha.class"), []byte{}, 0600)).To(Succeed())
+
+		dep := libpak.BuildModuleDependency{
+			Version: "11.0.0",
+			URI:     "https://localhost/stub-jre-11.tar.gz",
+			SHA256:  "3aa01010c0d3592ea248c8353d60b361231fa9bf9a7479b4f06451fef3e64524",
+		}
+		dc := libpak.DependencyCache{CachePath: "testdata", Logger: log.NewDiscardLogger()}
+
+		j, err := jvmvendors.NewJRE(ctx.ApplicationPath, dep, dc, jvmvendors.JREType, cl, LaunchContribution)
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(j.Contribute(&layer)).To(Succeed())
+
+		fingerprint, err := count.Fingerprint(ctx.ApplicationPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.LaunchEnvironment["BPI_APP_CLASS_COUNT.default"]).To(Equal("1"))
+		Expect(layer.LaunchEnvironment["BPI_APP_CLASS_COUNT_FINGERPRINT.default"]).To(Equal(fingerprint))
+
+		// the application is counted again when the JVM layer is reused
+		Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "bravo.class"), []byte{}, 0600)).To(Succeed())
+		layer.LaunchEnvironment = libcnb.Environment{}
+
+		Expect(j.Contribute(&layer)).To(Succeed())
+
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("BPI_JVM_CLASS_COUNT.default"))
+		Expect(layer.LaunchEnvironment["BPI_APP_CLASS_COUNT.default"]).To(Equal("2"))
+	})
+
+	it("does not count application classes built from source", func() {
+		Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "pom.xml"), []byte{}, 0600)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(ctx.ApplicationPath, ".mvn", "wrapper"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, ".mvn", "wrapper", "alpha.class"), []byte{}, 0600)).To(Succeed())
+
+		dep := libpak.BuildModuleDependency{
+			Version: "11.0.0",
+			URI:     "https://localhost/stub-jre-11.tar.gz",
+			SHA256:  "3aa01010c0d3592ea248c8353d60b361231fa9bf9a7479b4f06451fef3e64524",
+		}
+		dc := libpak.DependencyCache{CachePath: "testdata", Logger: log.NewDiscardLogger()}
+
+		j, err := jvmvendors.NewJRE(ctx.ApplicationPath, dep, dc, jvmvendors.JREType, cl, LaunchContribution)
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(j.Contribute(&layer)).To(Succeed())
+
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("BPI_APP_CLASS_COUNT.default"))
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("BPI_APP_CLASS_COUNT_FINGERPRINT.default"))
+	})
+
 	it("marks after Java 9 JRE layer for launch", func() {
 		dep := libpak.BuildModuleDependency{
 			Version: "11.0.0",
@@ -18,6 +18,7 @@ package jvmvendors
 
 import (
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -28,12 +29,22 @@ import (
 	"software.sslmate.com/src/go-pkcs12"
 )
 
+// ErrReadOnlyKeystore is returned when adding certificates to a keystore whose format can only be read.
+var ErrReadOnlyKeystore = errors.New("adding certificates to the keystore format is not supported")
+
 type Keystore interface {
 	Add(string, *pem.Block) error
+	Entries() ([]KeystoreEntry, error)
 	Write() error
 }
 
-func DetectKeystore(location string) (Keystore, error) {
+// KeystoreEntry is a trusted certificate and the alias it is stored under.
+type KeystoreEntry struct {
+	Alias       string
+	Certificate *x509.Certificate
+}
+
+func DetectKeystore(location string, password string) (Keystore, error) {
 	buf, err := os.ReadFile(location)
 	if err != nil {
 		return nil, err
@@ -44,10 +55,30 @@ func DetectKeystore(location string) (Keystore, error) {
 	}
 
 	if len(buf) > 3 && buf[0] == 0xFE && buf[1] == 0xED && buf[2] == 0xFE && buf[3] == 0xED {
-		return NewJKSKeystore(location, "changeit")
+		return NewJKSKeystore(location, password)
 	}
 
-	return NewPasswordLessPKCS12Keystore(location)
+	// both PKCS12 and BCFKS are DER encoded sequences, a PKCS12 PFX starts with its version number while a BCFKS
+	// object store starts with its (possibly encrypted) store data
+	var outer, first asn1.RawValue
+	if _, err := asn1.Unmarshal(buf, &outer); err != nil || outer.Tag != asn1.TagSequence {
+		return nil, fmt.Errorf("unsupported keystore format in %s, expected JKS, PKCS12 or BCFKS", location)
+	}
+	if _, err := asn1.Unmarshal(outer.Bytes, &first); err != nil {
+		return nil, fmt.Errorf("unsupported keystore format in %s, expected JKS, PKCS12 or BCFKS", location)
+	}
+
+	switch first.Tag {
+	case asn1.TagInteger:
+		if _, err := pkcs12.DecodeTrustStore(buf, ""); err == nil {
+			return NewPasswordLessPKCS12Keystore(location)
+		}
+		return NewPKCS12Keystore(location, password)
+	case asn1.TagSequence:
+		return NewBCFKSKeystore(location, password)
+	default:
+		return nil, fmt.Errorf("unsupported keystore format in %s, expected JKS, PKCS12 or BCFKS", location)
+	}
 }
 
 var _ Keystore = &JKSKeystore{}
@@ -90,6 +121,28 @@ func (k *JKSKeystore) Add(name string, b *pem.Block) error {
 	return nil
 }
 
+func (k *JKSKeystore) Entries() ([]KeystoreEntry, error) {
+	var entries []KeystoreEntry
+	for _, alias := range k.store.Aliases() {
+		if !k.store.IsTrustedCertificateEntry(alias) {
+			continue
+		}
+
+		e, err := k.store.GetTrustedCertificateEntry(alias)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get trusted entry %s\n%w", alias, err)
+		}
+
+		cert, err := x509.ParseCertificate(e.Certificate.Content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate %s\n%w", alias, err)
+		}
+
+		entries = append(entries, KeystoreEntry{Alias: alias, Certificate: cert})
+	}
+	return entries, nil
+}
+
 func (k *JKSKeystore) Write() error {
 	if unix.Access(k.location, unix.W_OK) != nil {
 		return nil
@@ -130,17 +183,9 @@ func NewPasswordLessPKCS12Keystore(location string) (*PasswordLessPKCS12Keystore
 		return nil, err
 	}
 
-	var entries []pkcs12.TrustStoreEntry
-	for _, x509Cert := range x509Certs {
-		entries = append(entries, pkcs12.TrustStoreEntry{
-			Cert:         x509Cert,
-			FriendlyName: x509Cert.Subject.String(),
-		})
-	}
-
 	return &PasswordLessPKCS12Keystore{
 		location: location,
-		entries:  entries,
+		entries:  pkcs12TrustStoreEntries(in, "", x509Certs),
 	}, nil
 }
 
@@ -158,6 +203,10 @@ func (k *PasswordLessPKCS12Keystore) Add(name string, b *pem.Block) error {
 	return nil
 }
 
+func (k *PasswordLessPKCS12Keystore) Entries() ([]KeystoreEntry, error) {
+	return pkcs12Entries(k.entries), nil
+}
+
 func (k *PasswordLessPKCS12Keystore) Write() error {
 	if unix.Access(k.location, unix.W_OK) != nil {
 		return nil
@@ -179,3 +228,76 @@ func (k *PasswordLessPKCS12Keystore) Write() error {
 func (k *PasswordLessPKCS12Keystore) Len() int {
 	return len(k.entries)
 }
+
+var _ Keystore = &PKCS12Keystore{}
+
+type PKCS12Keystore struct {
+	location string
+	password string
+	entries  []pkcs12.TrustStoreEntry
+}
+
+func NewPKCS12Keystore(location, password string) (*PKCS12Keystore, error) {
+	in, err := os.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	x509Certs, err := pkcs12.DecodeTrustStore(in, password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode keystore\n%w", err)
+	}
+
+	return &PKCS12Keystore{
+		location: location,
+		password: password,
+		entries:  pkcs12TrustStoreEntries(in, password, x509Certs),
+	}, nil
+}
+
+func (k *PKCS12Keystore) Add(name string, b *pem.Block) error {
+	cert, err := x509.ParseCertificate(b.Bytes)
+	if err != nil {
+		return err
+	}
+
+	k.entries = append(k.entries, pkcs12.TrustStoreEntry{
+		Cert:         cert,
+		FriendlyName: name,
+	})
+
+	return nil
+}
+
+func (k *PKCS12Keystore) Entries() ([]KeystoreEntry, error) {
+	return pkcs12Entries(k.entries), nil
+}
+
+func (k *PKCS12Keystore) Write() error {
+	if unix.Access(k.location, unix.W_OK) != nil {
+		return nil
+	}
+
+	data, err := pkcs12.Modern2023.EncodeTrustStoreEntries(k.entries, k.password)
+	if err != nil {
+		return fmt.Errorf("unable to encode keystore\n%w", err)
+	}
+
+	if err := os.WriteFile(k.location, data, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", k.location, err)
+	}
+
+	return nil
+}
+
+func (k *PKCS12Keystore) Len() int {
+	return len(k.entries)
+}
+
+func pkcs12Entries(in []pkcs12.TrustStoreEntry) []KeystoreEntry {
+	var entries []KeystoreEntry
+	for _, e := range in {
+		entries = append(entries, KeystoreEntry{Alias: e.FriendlyName, Certificate: e.Cert})
+	}
+	return entries
+}
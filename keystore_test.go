@@ -17,6 +17,7 @@
 package jvmvendors_test
 
 import (
+	"crypto/x509"
 	"encoding/pem"
 	"io"
 	"os"
@@ -25,6 +26,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
+	"software.sslmate.com/src/go-pkcs12"
 
 	jvmvendors "github.com/paketo-buildpacks/jvm-vendors"
 )
@@ -56,11 +58,22 @@ func testKeystore(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("is detected correctly", func() {
-			ks, err := jvmvendors.DetectKeystore(path)
+			ks, err := jvmvendors.DetectKeystore(path, "changeit")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ks).To(BeAssignableToTypeOf(&jvmvendors.JKSKeystore{}))
 		})
 
+		it("lists entries", func() {
+			ks, err := jvmvendors.NewJKSKeystore(path, "changeit")
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := ks.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Alias).NotTo(BeEmpty())
+			Expect(entries[0].Certificate).NotTo(BeNil())
+		})
+
 		it("can be written", func() {
 			ks, err := jvmvendors.NewJKSKeystore(path, "changeit")
 			Expect(err).ToNot(HaveOccurred())
@@ -92,11 +105,21 @@ func testKeystore(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("is detected correctly", func() {
-			ks, err := jvmvendors.DetectKeystore(path)
+			ks, err := jvmvendors.DetectKeystore(path, "changeit")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ks).To(BeAssignableToTypeOf(&jvmvendors.PasswordLessPKCS12Keystore{}))
 		})
 
+		it("lists entries", func() {
+			ks, err := jvmvendors.NewPasswordLessPKCS12Keystore(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := ks.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Alias).To(Equal(entries[0].Certificate.Subject.String()))
+		})
+
 		it("can be written", func() {
 			ks, err := jvmvendors.NewPasswordLessPKCS12Keystore(path)
 			Expect(err).ToNot(HaveOccurred())
@@ -111,4 +134,167 @@ func testKeystore(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	context("password protected pkcs12 keystore", func() {
+		it.Before(func() {
+			in, err := os.ReadFile(filepath.Join("testdata", "cert.pem"))
+			Expect(err).NotTo(HaveOccurred())
+			block, _ := pem.Decode(in)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := pkcs12.Modern2023.EncodeTrustStoreEntries([]pkcs12.TrustStoreEntry{{Cert: cert, FriendlyName: "test-alias"}}, "test-password")
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := os.CreateTemp("", "certificate-loader")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = out.Close() }()
+
+			_, err = out.Write(data)
+			Expect(err).NotTo(HaveOccurred())
+
+			path = out.Name()
+		})
+
+		it("is detected correctly", func() {
+			ks, err := jvmvendors.DetectKeystore(path, "test-password")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(BeAssignableToTypeOf(&jvmvendors.PKCS12Keystore{}))
+		})
+
+		it("fails with an incorrect password", func() {
+			_, err := jvmvendors.DetectKeystore(path, "changeit")
+			Expect(err).To(MatchError(ContainSubstring("unable to decode keystore")))
+		})
+
+		it("can be written", func() {
+			ks, err := jvmvendors.NewPKCS12Keystore(path, "test-password")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ks.Len()).To(Equal(1))
+			cert, err := os.ReadFile(filepath.Join("testdata", "cert.pem"))
+			Expect(err).ToNot(HaveOccurred())
+			block, _ := pem.Decode(cert)
+			Expect(ks.Add("foo", block)).To(Succeed())
+			Expect(ks.Write()).To(Succeed())
+
+			ks, err = jvmvendors.NewPKCS12Keystore(path, "test-password")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ks.Len()).To(Equal(2))
+		})
+
+		it("keeps the aliases of existing entries", func() {
+			ks, err := jvmvendors.NewPKCS12Keystore(path, "test-password")
+			Expect(err).ToNot(HaveOccurred())
+			cert, err := os.ReadFile(filepath.Join("testdata", "cert.pem"))
+			Expect(err).ToNot(HaveOccurred())
+			block, _ := pem.Decode(cert)
+			Expect(ks.Add("foo", block)).To(Succeed())
+			Expect(ks.Write()).To(Succeed())
+
+			ks, err = jvmvendors.NewPKCS12Keystore(path, "test-password")
+			Expect(err).ToNot(HaveOccurred())
+			entries, err := ks.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Alias).To(Equal("test-alias"))
+			Expect(entries[1].Alias).To(Equal("foo"))
+		})
+
+		it("falls back to subjects as aliases for legacy encryption", func() {
+			in, err := os.ReadFile(filepath.Join("testdata", "cert.pem"))
+			Expect(err).NotTo(HaveOccurred())
+			block, _ := pem.Decode(in)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := pkcs12.LegacyDES.EncodeTrustStoreEntries([]pkcs12.TrustStoreEntry{{Cert: cert, FriendlyName: "test-alias"}}, "test-password")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(path, data, 0644)).To(Succeed())
+
+			ks, err := jvmvendors.NewPKCS12Keystore(path, "test-password")
+			Expect(err).ToNot(HaveOccurred())
+			entries, err := ks.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Alias).To(Equal(cert.Subject.String()))
+		})
+	})
+
+	context("bcfks keystore", func() {
+		// test-keystore.bcfks holds testdata/cert.pem under the password changeit. It was encoded by this package rather
+		// than by Bouncy Castle, and should be replaced by a store created with the Bouncy Castle FIPS provider:
+		//
+		//   keytool -importcert -noprompt -alias test -file testdata/cert.pem \
+		//     -keystore testdata/test-keystore.bcfks -storetype BCFKS -storepass changeit \
+		//     -providername BCFIPS -providerclass org.bouncycastle.jcajce.provider.BouncyCastleFipsProvider \
+		//     -providerpath bc-fips.jar
+		it.Before(func() {
+			in, err := os.Open(filepath.Join("testdata", "test-keystore.bcfks"))
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = in.Close() }()
+
+			out, err := os.CreateTemp("", "certificate-loader")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = out.Close() }()
+
+			_, err = io.Copy(out, in)
+			Expect(err).NotTo(HaveOccurred())
+
+			path = out.Name()
+		})
+
+		it("is detected correctly", func() {
+			ks, err := jvmvendors.DetectKeystore(path, "changeit")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ks).To(BeAssignableToTypeOf(&jvmvendors.BCFKSKeystore{}))
+		})
+
+		it("lists entries", func() {
+			ks, err := jvmvendors.NewBCFKSKeystore(path, "changeit")
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := ks.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Certificate).NotTo(BeNil())
+		})
+
+		it("fails with an incorrect password", func() {
+			_, err := jvmvendors.DetectKeystore(path, "another-password")
+			Expect(err).To(MatchError(ContainSubstring("keystore password is incorrect")))
+		})
+
+		it("is read-only", func() {
+			before, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			ks, err := jvmvendors.NewBCFKSKeystore(path, "changeit")
+			Expect(err).ToNot(HaveOccurred())
+			cert, err := os.ReadFile(filepath.Join("testdata", "cert.pem"))
+			Expect(err).ToNot(HaveOccurred())
+			block, _ := pem.Decode(cert)
+			Expect(ks.Add("foo", block)).To(MatchError(jvmvendors.ErrReadOnlyKeystore))
+			Expect(ks.Write()).To(Succeed())
+
+			Expect(os.ReadFile(path)).To(Equal(before))
+		})
+	})
+
+	context("unknown keystore", func() {
+		it.Before(func() {
+			out, err := os.CreateTemp("", "certificate-loader")
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = out.Close() }()
+
+			_, err = out.Write([]byte{0xCE, 0xCE, 0xCE, 0xCE, 0x00, 0x00, 0x00, 0x02})
+			Expect(err).NotTo(HaveOccurred())
+
+			path = out.Name()
+		})
+
+		it("returns an error", func() {
+			_, err := jvmvendors.DetectKeystore(path, "changeit")
+			Expect(err).To(MatchError(ContainSubstring("unsupported keystore format")))
+		})
+	})
 }
@@ -129,7 +129,9 @@ func worker(paths chan string, results chan result, wg *sync.WaitGroup) {
 	wg.Done()
 }
 
-func process(path string) (MavenJAR, error) {
+// NewMavenJAR returns the name and version of the JAR at path, without hashing it. JARs that do not follow Maven naming
+// conventions are named by their file name, with an unknown version.
+func NewMavenJAR(path string) MavenJAR {
 	m := MavenJAR{
 		Name:    filepath.Base(path),
 		Version: "unknown",
@@ -140,6 +142,12 @@ func process(path string) (MavenJAR, error) {
 		m.Version = p[2]
 	}
 
+	return m
+}
+
+func process(path string) (MavenJAR, error) {
+	m := NewMavenJAR(path)
+
 	s := sha256.New()
 
 	in, err := os.Open(path)
@@ -62,4 +62,14 @@ func testMavenJARListing(t *testing.T, context spec.G, it spec.S) {
 			}))
 		}
 	})
+	it("names maven JARs without hashing them", func() {
+		Expect(jvmvendors.NewMavenJAR(filepath.Join("BOOT-INF", "lib", "netty-buffer-4.1.100.Final.jar"))).To(Equal(jvmvendors.MavenJAR{
+			Name:    "netty-buffer",
+			Version: "4.1.100.Final",
+		}))
+		Expect(jvmvendors.NewMavenJAR(filepath.Join("lib", "application.jar"))).To(Equal(jvmvendors.MavenJAR{
+			Name:    "application.jar",
+			Version: "unknown",
+		}))
+	})
 }
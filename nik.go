@@ -108,7 +108,7 @@ func (n NIK) Contribute(layer *libcnb.Layer) error {
 			return fmt.Errorf("unable to set keystore file permissions\n%w", err)
 		}
 
-		if err := n.CertificateLoader.Load(keyStorePath, "changeit"); err != nil {
+		if err := n.CertificateLoader.Load(keyStorePath, n.CertificateLoader.Password); err != nil {
 			return fmt.Errorf("unable to load certificates\n%w", err)
 		}
 
@@ -40,6 +40,7 @@ func testNIK(t *testing.T, context spec.G, it spec.S) {
 
 		cl = jvmvendors.CertificateLoader{
 			CertDirs: []string{filepath.Join("testdata", "certificates")},
+			Password: "changeit",
 			Logger:   log.NewDiscardLogger(),
 		}
 
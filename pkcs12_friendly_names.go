@@ -0,0 +1,263 @@
+/*
+ * Copyright 2018-2026 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jvmvendors
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	oidPKCS7Data          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7EncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidPKCS12CertBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS9FriendlyName  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidHMACWithSHA1       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type pkcs12PFX struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type pkcs12EncryptedData struct {
+	Version              int
+	EncryptedContentInfo struct {
+		ContentType                asn1.ObjectIdentifier
+		ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+		EncryptedContent           []byte `asn1:"tag:0,optional"`
+	}
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+// pkcs12FriendlyNames returns the friendly names of the certificate bags of a PKCS12 keystore, in the order that
+// pkcs12.DecodeTrustStore returns their certificates. Certificate bags that are not encrypted or encrypted with PBES2
+// and AES, the default of keytool since Java 12 and 8u301, are supported.
+func pkcs12FriendlyNames(data []byte, password string) ([]string, error) {
+	var pfx pkcs12PFX
+	if err := unmarshalDER(data, &pfx); err != nil {
+		return nil, fmt.Errorf("unable to decode PKCS12 keystore\n%w", err)
+	}
+
+	var authSafe []byte
+	if !pfx.AuthSafe.ContentType.Equal(oidPKCS7Data) {
+		return nil, fmt.Errorf("unsupported PKCS12 content type %s", pfx.AuthSafe.ContentType)
+	}
+	if err := unmarshalDER(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, fmt.Errorf("unable to decode PKCS12 authenticated safe\n%w", err)
+	}
+
+	var contents []pkcs12ContentInfo
+	if err := unmarshalDER(authSafe, &contents); err != nil {
+		return nil, fmt.Errorf("unable to decode PKCS12 authenticated safe\n%w", err)
+	}
+
+	var names []string
+	for _, c := range contents {
+		var safeContents []byte
+
+		switch {
+		case c.ContentType.Equal(oidPKCS7Data):
+			if err := unmarshalDER(c.Content.Bytes, &safeContents); err != nil {
+				return nil, fmt.Errorf("unable to decode PKCS12 safe contents\n%w", err)
+			}
+		case c.ContentType.Equal(oidPKCS7EncryptedData):
+			var encrypted pkcs12EncryptedData
+			if err := unmarshalDER(c.Content.Bytes, &encrypted); err != nil {
+				return nil, fmt.Errorf("unable to decode PKCS12 encrypted data\n%w", err)
+			}
+
+			var err error
+			info := encrypted.EncryptedContentInfo
+			if safeContents, err = pbes2Decrypt(info.ContentEncryptionAlgorithm, info.EncryptedContent, password); err != nil {
+				return nil, fmt.Errorf("unable to decrypt PKCS12 safe contents\n%w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported PKCS12 content type %s", c.ContentType)
+		}
+
+		var bags []pkcs12SafeBag
+		if err := unmarshalDER(safeContents, &bags); err != nil {
+			return nil, fmt.Errorf("unable to decode PKCS12 safe bags\n%w", err)
+		}
+
+		for _, b := range bags {
+			if !b.ID.Equal(oidPKCS12CertBag) {
+				continue
+			}
+
+			name, err := friendlyName(b.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// friendlyName returns the BMPString value of the friendlyName attribute, or an empty string if there is none.
+func friendlyName(attributes []pkcs12Attribute) (string, error) {
+	for _, a := range attributes {
+		if !a.ID.Equal(oidPKCS9FriendlyName) {
+			continue
+		}
+
+		var value asn1.RawValue
+		if err := unmarshalDER(a.Values.Bytes, &value); err != nil {
+			return "", fmt.Errorf("unable to decode PKCS12 friendly name\n%w", err)
+		}
+		if value.Tag != asn1.TagBMPString || len(value.Bytes)%2 != 0 {
+			return "", errors.New("PKCS12 friendly name is not a BMPString")
+		}
+
+		s := make([]uint16, len(value.Bytes)/2)
+		for i := range s {
+			s[i] = uint16(value.Bytes[2*i])<<8 | uint16(value.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(s)), nil
+	}
+
+	return "", nil
+}
+
+// pbes2Decrypt decrypts PBES2 (RFC 8018) content encrypted with PBKDF2 and AES-CBC, whose password, unlike that of
+// the PKCS12 key derivation, is UTF-8 encoded.
+func pbes2Decrypt(algorithm pkix.AlgorithmIdentifier, ciphertext []byte, password string) ([]byte, error) {
+	if !algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", algorithm.Algorithm)
+	}
+
+	var params bcfksPBES2Params
+	if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("unable to decode PBES2 parameters\n%w", err)
+	}
+
+	var keyLength int
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLength = 16
+	case scheme.Equal(oidAES192CBC):
+		keyLength = 24
+	case scheme.Equal(oidAES256CBC):
+		keyLength = 32
+	default:
+		return nil, fmt.Errorf("unsupported encryption scheme %s", scheme)
+	}
+
+	var iv []byte
+	if err := unmarshalDER(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("unable to decode AES-CBC initialization vector")
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %s", params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdf bcfksPBKDF2Params
+	if err := unmarshalDER(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("unable to decode PBKDF2 parameters\n%w", err)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0 || kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 pseudo-random function %s", kdf.PRF.Algorithm)
+	}
+
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.IterationCount, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid AES-CBC ciphertext length")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("invalid AES-CBC padding, the keystore password may be incorrect")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// pkcs12TrustStoreEntries pairs the certificates of a PKCS12 keystore with their friendly names, falling back to their
+// subjects if the friendly names cannot be read, such as from keystores encrypted with the legacy PKCS12 algorithms.
+func pkcs12TrustStoreEntries(data []byte, password string, certs []*x509.Certificate) []pkcs12.TrustStoreEntry {
+	names, err := pkcs12FriendlyNames(data, password)
+	if err != nil || len(names) != len(certs) {
+		names = nil
+	}
+
+	var entries []pkcs12.TrustStoreEntry
+	for i, cert := range certs {
+		name := cert.Subject.String()
+		if names != nil && names[i] != "" {
+			name = names[i]
+		}
+		entries = append(entries, pkcs12.TrustStoreEntry{Cert: cert, FriendlyName: name})
+	}
+	return entries
+}